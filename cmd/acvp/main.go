@@ -0,0 +1,238 @@
+// Command acvp is an offline ACVP client-side harness for the hasher package. It reads an ACVP SHA2
+// prompt (vector set) JSON file, computes every AFT, MCT and LDT test case with the hasher package and
+// writes the matching response JSON, ready to submit to (or compare against) a local stand-in server.
+// The JSON layout follows the ACVP SHA specification at https://pages.nist.gov/ACVP/draft-celi-acvp-sha.html
+//
+// Usage:
+//
+//	acvp -prompt prompt.json -response response.json
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"hasher"
+	"io"
+	"log"
+	"os"
+	"strings"
+)
+
+// acvpAlgorithms maps the ACVP algorithm names to the HashAlgorithm under test
+var acvpAlgorithms = map[string]hasher.HashAlgorithm{
+	"SHA2-224":     hasher.Sha224,
+	"SHA2-256":     hasher.Sha256,
+	"SHA2-384":     hasher.Sha384,
+	"SHA2-512":     hasher.Sha512,
+	"SHA2-512/224": hasher.Sha512t224,
+	"SHA2-512/256": hasher.Sha512t256,
+}
+
+// Prompt structures
+type vectorSet struct {
+	VsID       int         `json:"vsId"`
+	Algorithm  string      `json:"algorithm"`
+	Revision   string      `json:"revision"`
+	IsSample   bool        `json:"isSample"`
+	TestGroups []testGroup `json:"testGroups"`
+}
+
+type testGroup struct {
+	TgID       int        `json:"tgId"`
+	TestType   string     `json:"testType"`
+	MctVersion string     `json:"mctVersion"`
+	Tests      []testCase `json:"tests"`
+}
+
+type testCase struct {
+	TcID     int           `json:"tcId"`
+	Msg      string        `json:"msg"`
+	Len      uint64        `json:"len"`
+	LargeMsg *largeMessage `json:"largeMsg"`
+}
+
+type largeMessage struct {
+	Content            string `json:"content"`
+	ContentLength      uint64 `json:"contentLength"`
+	FullLength         uint64 `json:"fullLength"`
+	ExpansionTechnique string `json:"expansionTechnique"`
+}
+
+// Response structures
+type vectorSetResponse struct {
+	VsID       int             `json:"vsId"`
+	Algorithm  string          `json:"algorithm"`
+	Revision   string          `json:"revision"`
+	TestGroups []groupResponse `json:"testGroups"`
+}
+
+type groupResponse struct {
+	TgID  int            `json:"tgId"`
+	Tests []caseResponse `json:"tests"`
+}
+
+type caseResponse struct {
+	TcID         int         `json:"tcId"`
+	Md           string      `json:"md,omitempty"`
+	ResultsArray []mctResult `json:"resultsArray,omitempty"`
+}
+
+type mctResult struct {
+	Md string `json:"md"`
+}
+
+func main() {
+	var promptFile = flag.String("prompt", "", "ACVP prompt JSON file (default stdin)")
+	var responseFile = flag.String("response", "", "response JSON file to write (default stdout)")
+	flag.Parse()
+
+	var prompt io.Reader = os.Stdin
+	if *promptFile != "" {
+		file, err := os.Open(*promptFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		prompt = file
+	}
+	var response io.Writer = os.Stdout
+	if *responseFile != "" {
+		file, err := os.Create(*responseFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		response = file
+	}
+	if err := run(prompt, response); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run decodes the prompt array ([{"acvVersion": ...}, {vector set}...]) and encodes the response array
+func run(prompt io.Reader, response io.Writer) error {
+	var elements []json.RawMessage
+	if err := json.NewDecoder(prompt).Decode(&elements); err != nil {
+		return fmt.Errorf("decoding prompt: %v", err)
+	}
+	var responses []interface{}
+	for _, element := range elements {
+		var header map[string]interface{}
+		if err := json.Unmarshal(element, &header); err != nil {
+			return fmt.Errorf("decoding prompt: %v", err)
+		}
+		if _, ok := header["testGroups"]; !ok { // The version object (or other metadata) is echoed back
+			responses = append(responses, header)
+			continue
+		}
+		var prompt vectorSet
+		if err := json.Unmarshal(element, &prompt); err != nil {
+			return fmt.Errorf("decoding vector set: %v", err)
+		}
+		result, err := processVectorSet(prompt)
+		if err != nil {
+			return fmt.Errorf("vsId %v: %v", prompt.VsID, err)
+		}
+		responses = append(responses, result)
+	}
+	var encoder = json.NewEncoder(response)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(responses)
+}
+
+// processVectorSet computes the response for every test group of one vector set
+func processVectorSet(prompt vectorSet) (vectorSetResponse, error) {
+	var result = vectorSetResponse{VsID: prompt.VsID, Algorithm: prompt.Algorithm, Revision: prompt.Revision}
+	hashAlgorithm, ok := acvpAlgorithms[prompt.Algorithm]
+	if !ok {
+		return result, fmt.Errorf("unsupported algorithm %q", prompt.Algorithm)
+	}
+	for _, group := range prompt.TestGroups {
+		var groupResult = groupResponse{TgID: group.TgID}
+		for _, test := range group.Tests {
+			var caseResult = caseResponse{TcID: test.TcID}
+			var err error
+			switch group.TestType {
+			case "AFT":
+				caseResult.Md, err = algorithmFunctionalTest(hashAlgorithm, test)
+			case "MCT":
+				if group.MctVersion != "" && group.MctVersion != "standard" {
+					err = fmt.Errorf("unsupported mctVersion %q", group.MctVersion)
+					break
+				}
+				caseResult.ResultsArray, err = monteCarloTest(hashAlgorithm, test)
+			case "LDT":
+				caseResult.Md, err = largeDataTest(hashAlgorithm, test)
+			default:
+				err = fmt.Errorf("unsupported testType %q", group.TestType)
+			}
+			if err != nil {
+				return result, fmt.Errorf("tgId %v tcId %v: %v", group.TgID, test.TcID, err)
+			}
+			groupResult.Tests = append(groupResult.Tests, caseResult)
+		}
+		result.TestGroups = append(result.TestGroups, groupResult)
+	}
+	return result, nil
+}
+
+// algorithmFunctionalTest hashes msg, honoring a len (in bits) that need not be byte aligned
+func algorithmFunctionalTest(hashAlgorithm hasher.HashAlgorithm, test testCase) (string, error) {
+	message, err := hex.DecodeString(test.Msg)
+	if err != nil || test.Len > uint64(len(message))*8 {
+		return "", fmt.Errorf("malformed msg/len")
+	}
+	return fmt.Sprintf("%X", hasher.New(hashAlgorithm).WriteBits(message, test.Len).Sum()), nil
+}
+
+// monteCarloTest runs the standard SHA-2 Monte Carlo procedure: 100 checkpoints of 1000 chained hashes
+func monteCarloTest(hashAlgorithm hasher.HashAlgorithm, test testCase) ([]mctResult, error) {
+	seed, err := hex.DecodeString(test.Msg)
+	if err != nil {
+		return nil, fmt.Errorf("malformed msg")
+	}
+	var results []mctResult
+	for j := 0; j < 100; j++ {
+		var md = [3][]byte{seed, seed, seed}
+		for i := 3; i < 1003; i++ {
+			var next, _ = hex.DecodeString(fmt.Sprintf("%x", hasher.New(hashAlgorithm).
+				Write(md[0]).Write(md[1]).Write(md[2]).Sum()))
+			md = [3][]byte{md[1], md[2], next}
+		}
+		seed = md[2]
+		results = append(results, mctResult{Md: strings.ToUpper(hex.EncodeToString(seed))})
+	}
+	return results, nil
+}
+
+// largeDataTest streams the "repeating" expansion of content until fullLength bits have been hashed
+func largeDataTest(hashAlgorithm hasher.HashAlgorithm, test testCase) (string, error) {
+	var large = test.LargeMsg
+	if large == nil || large.ExpansionTechnique != "repeating" {
+		return "", fmt.Errorf("unsupported largeMsg expansion")
+	}
+	content, err := hex.DecodeString(large.Content)
+	if err != nil || large.ContentLength == 0 || large.ContentLength%8 > 0 || large.FullLength%8 > 0 ||
+		large.ContentLength > uint64(len(content))*8 {
+		return "", fmt.Errorf("malformed largeMsg")
+	}
+	content = content[:large.ContentLength/8]
+
+	// Repeat the content into a buffer of about 1 MiB so each Write hits the block-by-block fast path
+	var buffer []byte
+	for len(buffer) < 1<<20 {
+		buffer = append(buffer, content...)
+	}
+	var instance = hasher.New(hashAlgorithm)
+	for remaining := large.FullLength / 8; remaining > 0; {
+		var length = uint64(len(buffer))
+		if remaining < length {
+			length = remaining
+		}
+		instance.Write(buffer[:length])
+		remaining -= length
+	}
+	return fmt.Sprintf("%X", instance.Sum()), nil
+}
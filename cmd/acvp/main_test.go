@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func assertEquals(t *testing.T, expected interface{}, actual interface{}, message interface{}) {
+	if expected != actual {
+		t.Error(fmt.Sprintf("Expected %v,\n    got %v\n %v", expected, actual, message))
+	}
+}
+
+const prompt = `[
+  {"acvVersion": "1.0"},
+  {"vsId": 1, "algorithm": "SHA2-256", "revision": "1.0", "isSample": true, "testGroups": [
+    {"tgId": 1, "testType": "AFT", "tests": [
+      {"tcId": 1, "msg": "", "len": 0},
+      {"tcId": 2, "msg": "616263", "len": 24},
+      {"tcId": 3, "msg": "68", "len": 5}]},
+    {"tgId": 2, "testType": "MCT", "mctVersion": "standard", "tests": [
+      {"tcId": 4, "msg": "6D1E72AD03DDEB5DE891E572E2396F8DA015D899EF0E79503152D6010A3FE691", "len": 256}]},
+    {"tgId": 3, "testType": "LDT", "tests": [
+      {"tcId": 5, "largeMsg": {"content": "DE26", "contentLength": 16, "fullLength": 8000016,
+        "expansionTechnique": "repeating"}}]}]},
+  {"vsId": 2, "algorithm": "SHA2-512/256", "revision": "1.0", "testGroups": [
+    {"tgId": 1, "testType": "AFT", "tests": [{"tcId": 1, "msg": "616263", "len": 24}]}]}
+]`
+
+func TestRun(t *testing.T) {
+	var response bytes.Buffer
+	if err := run(strings.NewReader(prompt), &response); err != nil {
+		t.Fatal(err)
+	}
+	var decoded []struct {
+		AcvVersion string          `json:"acvVersion"`
+		VsID       int             `json:"vsId"`
+		Algorithm  string          `json:"algorithm"`
+		TestGroups []groupResponse `json:"testGroups"`
+	}
+	if err := json.Unmarshal(response.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, len(decoded), "response elements")
+	assertEquals(t, "1.0", decoded[0].AcvVersion, "acvVersion echoed")
+
+	// AFT, including the bit-oriented 5 bit message 01101
+	var aft = decoded[1].TestGroups[0].Tests
+	var empty, abc = sha256.Sum256(nil), sha256.Sum256([]byte("abc"))
+	assertEquals(t, strings.ToUpper(hex.EncodeToString(empty[:])), aft[0].Md, "AFT len=0")
+	assertEquals(t, strings.ToUpper(hex.EncodeToString(abc[:])), aft[1].Md, "AFT len=24")
+	assertEquals(t, "D6D3E02A31A84A8CAA9718ED6C2057BE09DB45E7823EB5079CE7A573A3760F95", aft[2].Md, "AFT len=5")
+
+	// MCT chaining against crypto/sha256
+	var seed, _ = hex.DecodeString("6D1E72AD03DDEB5DE891E572E2396F8DA015D899EF0E79503152D6010A3FE691")
+	var mct = decoded[1].TestGroups[1].Tests[0].ResultsArray
+	assertEquals(t, 100, len(mct), "MCT checkpoints")
+	for j := 0; j < 100 && j < len(mct); j++ {
+		var md = [3][]byte{seed, seed, seed}
+		for i := 3; i < 1003; i++ {
+			next := sha256.Sum256(append(append(append([]byte{}, md[0]...), md[1]...), md[2]...))
+			md = [3][]byte{md[1], md[2], next[:]}
+		}
+		seed = md[2]
+		assertEquals(t, strings.ToUpper(hex.EncodeToString(seed)), mct[j].Md, fmt.Sprintf("MCT j=%v", j))
+	}
+
+	// LDT: 8000016 bits of repeated DE26
+	var large = bytes.Repeat([]byte{0xde, 0x26}, 500001)
+	var ldt = sha256.Sum256(large)
+	assertEquals(t, strings.ToUpper(hex.EncodeToString(ldt[:])), decoded[1].TestGroups[2].Tests[0].Md, "LDT")
+
+	var abc512t256 = sha512.Sum512_256([]byte("abc"))
+	assertEquals(t, strings.ToUpper(hex.EncodeToString(abc512t256[:])), decoded[2].TestGroups[0].Tests[0].Md,
+		"SHA2-512/256 AFT")
+}
+
+func TestRunUnsupported(t *testing.T) {
+	var testCases = []string{
+		`[{"vsId": 1, "algorithm": "SHA3-256", "testGroups": []}]`,
+		`[{"vsId": 1, "algorithm": "SHA2-256", "testGroups": [{"tgId": 1, "testType": "XYZ", "tests": [{}]}]}]`,
+		`[{"vsId": 1, "algorithm": "SHA2-256", "testGroups": [{"tgId": 1, "testType": "MCT",
+			"mctVersion": "alternate", "tests": [{"tcId": 1, "msg": "00", "len": 8}]}]}]`,
+		`[{"vsId": 1, "algorithm": "SHA2-256", "testGroups": [{"tgId": 1, "testType": "AFT",
+			"tests": [{"tcId": 1, "msg": "zz", "len": 8}]}]}]`,
+		`not json`,
+	}
+	for _, tt := range testCases {
+		var response bytes.Buffer
+		assertEquals(t, true, run(strings.NewReader(tt), &response) != nil, tt)
+	}
+}
@@ -0,0 +1,117 @@
+package hasher
+
+import (
+	"os"
+	"unsafe"
+)
+
+// Test hooks into un-exported package state (compiled only with the tests)
+
+// SelfTestVectors exposes the known-answer digests so tests can force a self-test failure
+var SelfTestVectors = selfTestVectors
+
+// ResetSelfTest discards all self-test results and leaves the error state
+func ResetSelfTest() {
+	selfTestState.Lock()
+	defer selfTestState.Unlock()
+	selfTestState.errorState.Store(false)
+	selfTestState.powerOnRun.Store(false)
+	selfTestState.results = nil
+	selfTestState.tested.Store(0)
+}
+
+// StateMemory returns views (not copies) of the chaining state, pending block and any key of a hasher, so
+// tests can look at the very memory Destroy and ZeroizeOnSum are meant to wipe
+func StateMemory(hasher Hasher) [][]byte {
+	switch typed := hasher.(type) {
+	case *sha224:
+		return memory256(&typed.hasher256)
+	case *sha256:
+		return memory256(&typed.hasher256)
+	case *sha256t192:
+		return memory256(&typed.hasher256)
+	case *sha384:
+		return memory512(&typed.hasher512)
+	case *sha512:
+		return memory512(&typed.hasher512)
+	case *sha512t224:
+		return memory512(&typed.hasher512)
+	case *sha512t256:
+		return memory512(&typed.hasher512)
+	case *ripemd160:
+		return [][]byte{view(typed.HashBlock160), typed.TempBlock160[:]}
+	case *blake2b:
+		return [][]byte{view(typed.HashBlock2b), typed.TempBlock2b[:], typed.Parameters.Key}
+	case *blake2s:
+		return [][]byte{view(typed.HashBlock2s), typed.TempBlock2s[:], typed.Parameters.Key}
+	case *shake256t192:
+		return [][]byte{view(typed.State1600), typed.TempBlock136[:]}
+	}
+	return nil
+}
+
+// InspectCopyBuffer installs (or with nil removes) an inspector of the wiped hasherCopy buffer
+func InspectCopyBuffer(inspector func(buffer []byte)) {
+	if inspector == nil {
+		inspector = func(buffer []byte) {}
+	}
+	inspectCopyBuffer = inspector
+}
+
+func memory256(hasher *hasher256) [][]byte {
+	return [][]byte{view(hasher.HashBlock256), hasher.TempBlock256[:]}
+}
+
+func memory512(hasher *hasher512) [][]byte {
+	return [][]byte{view(hasher.HashBlock512), hasher.TempBlock512[:]}
+}
+
+// view reinterprets the memory of a fixed size array of words as bytes
+func view[T any](array *T) []byte {
+	return unsafe.Slice((*byte)(unsafe.Pointer(array)), unsafe.Sizeof(*array))
+}
+
+// UnregisterAlgorithm removes an algorithm added by RegisterAlgorithm (so tests can be repeated)
+func UnregisterAlgorithm(hashAlgorithm HashAlgorithm) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if _, ok := constructors[hashAlgorithm]; ok {
+		delete(algorithms, hashAlgorithm)
+		delete(constructors, hashAlgorithm)
+	}
+}
+
+// Kernels reports how many assembly SHA-256 and SHA-512 kernels this CPU supports
+func Kernels() (sha256 int, sha512 int) {
+	return len(cpuKernels256()), len(cpuKernels512())
+}
+
+// UseKernels selects the supported assembly kernels with these indices (-1 for the pure-Go kernels), whether or
+// not they reproduce the reference, and returns a function restoring the selection
+func UseKernels(sha256 int, sha512 int) (restore func()) {
+	var saved256, saved512 = block256Kernel, block512Kernel
+	block256Kernel, block512Kernel = nil, nil
+	if sha256 >= 0 {
+		block256Kernel = cpuKernels256()[sha256]
+	}
+	if sha512 >= 0 {
+		block512Kernel = cpuKernels512()[sha512]
+	}
+	return func() { block256Kernel, block512Kernel = saved256, saved512 }
+}
+
+// AfterStart runs changer on each file HashFile has started hashing (nil restores the default)
+func AfterStart(changer func(file *os.File)) {
+	if changer == nil {
+		changer = func(file *os.File) {}
+	}
+	afterStart = changer
+}
+
+// UseMapping selects memory-mapped (true, where the platform can) or buffered reads of regular files and
+// returns a function restoring the selection
+func UseMapping(mapping bool) (restore func()) {
+	var saved = mapFiles
+	mapFiles = mapping
+	return func() { mapFiles = saved }
+}
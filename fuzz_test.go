@@ -0,0 +1,106 @@
+package hasher_test
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	. "hasher"
+	"testing"
+)
+
+//
+// Native fuzz targets (go test -fuzz=FuzzSha256 etc.) with a committed seed corpus under testdata/fuzz
+// that covers the FillLine boundary cases. Each target writes the message in three arbitrary partitions,
+// takes a Copy and an InterimSum at an arbitrary point, and compares everything to a single-shot reference
+// (the standard library where it has the algorithm).
+//
+
+func FuzzSha224(f *testing.F) {
+	fuzzHasher(f, Sha224, func(message []byte) []byte { sum := sha256.Sum224(message); return sum[:] })
+}
+
+func FuzzSha256(f *testing.F) {
+	fuzzHasher(f, Sha256, func(message []byte) []byte { sum := sha256.Sum256(message); return sum[:] })
+}
+
+func FuzzSha384(f *testing.F) {
+	fuzzHasher(f, Sha384, func(message []byte) []byte { sum := sha512.Sum384(message); return sum[:] })
+}
+
+func FuzzSha512(f *testing.F) {
+	fuzzHasher(f, Sha512, func(message []byte) []byte { sum := sha512.Sum512(message); return sum[:] })
+}
+
+func FuzzSha512t224(f *testing.F) {
+	fuzzHasher(f, Sha512t224, func(message []byte) []byte { sum := sha512.Sum512_224(message); return sum[:] })
+}
+
+func FuzzSha512t256(f *testing.F) {
+	fuzzHasher(f, Sha512t256, func(message []byte) []byte { sum := sha512.Sum512_256(message); return sum[:] })
+}
+
+func FuzzSha256t192(f *testing.F) {
+	fuzzHasher(f, Sha256t192, func(message []byte) []byte { sum := sha256.Sum256(message); return sum[:24] })
+}
+
+func FuzzShake256t192(f *testing.F) {
+	fuzzHasher(f, Shake256t192, func(message []byte) []byte { return sha3.SumSHAKE256(message, 24) })
+}
+
+// RIPEMD-160 and BLAKE2 have no standard library reference, so these are consistency-only fuzzers: they check
+// partitioned writes, Copy and InterimSum against a single Write of the same implementation, not its output
+
+func FuzzRipemd160(f *testing.F) {
+	fuzzHasher(f, Ripemd160, nil)
+}
+
+func FuzzBlake2b(f *testing.F) {
+	fuzzHasher(f, Blake2b, nil)
+}
+
+func FuzzBlake2s(f *testing.F) {
+	fuzzHasher(f, Blake2s, nil)
+}
+
+// fuzzHasher checks partitioned writes, Copy and InterimSum against reference (or a single Write if nil)
+func fuzzHasher(f *testing.F, hashAlgorithm HashAlgorithm, reference func([]byte) []byte) {
+	if reference == nil {
+		reference = func(message []byte) []byte {
+			digest, _ := hex.DecodeString(fmt.Sprintf("%x", New(hashAlgorithm).Write(message).Sum()))
+			return digest
+		}
+	}
+	f.Fuzz(func(t *testing.T, message []byte, split1 uint16, split2 uint16, copyAt uint16) {
+		var length = len(message) + 1
+		var cuts = []int{int(split1) % length, int(split2) % length}
+		if cuts[0] > cuts[1] {
+			cuts[0], cuts[1] = cuts[1], cuts[0]
+		}
+		var copyPoint = int(copyAt) % length
+		var expected = fmt.Sprintf("%x", reference(message))
+
+		// Three partitions
+		var actual = New(hashAlgorithm).Write(message[:cuts[0]]).Write(message[cuts[0]:cuts[1]]).
+			Write(message[cuts[1]:]).Sum()
+		if fmt.Sprintf("%x", actual) != expected {
+			t.Fatalf("%v partitions %v: expected %v, got %x", hashAlgorithm, cuts, expected, actual)
+		}
+
+		// InterimSum and Copy (a serialized state round-trip) part way through
+		var original = New(hashAlgorithm).Write(message[:copyPoint])
+		var interim = original.InterimSum()
+		if fmt.Sprintf("%x", interim) != fmt.Sprintf("%x", reference(message[:copyPoint])) {
+			t.Fatalf("%v InterimSum at %v: expected %x, got %x", hashAlgorithm, copyPoint,
+				reference(message[:copyPoint]), interim)
+		}
+		var duplicate = original.Copy()
+		original.Write(message[copyPoint:])
+		duplicate.Write(message[copyPoint:])
+		if fmt.Sprintf("%x", original.Sum()) != expected || fmt.Sprintf("%x", duplicate.Sum()) != expected {
+			t.Fatalf("%v Copy at %v: expected %v, got %x and %x", hashAlgorithm, copyPoint, expected,
+				original.Sum(), duplicate.Sum())
+		}
+	})
+}
@@ -5,11 +5,43 @@
 // sensitive information and problems typically stem more from "design time" than "run time" errors,
 // the code "fails fast and fails hard" upon incorrect usage. There are no dependencies on packages
 // outside of the standard library. FIPS PUB 180-4 may be found at https://nvlpubs.nist.gov/nistpubs/FIPS/NIST.FIPS.180-4.pdf
+// RIPEMD-160 (https://homes.esat.kuleuven.be/~bosselae/ripemd160.html) is also provided, along with the
+// Hash160 helper, for Bitcoin address and script handling. BLAKE2b and BLAKE2s from RFC 7693 are provided
+// with variable digest size, keyed mode, salt and personalization via NewBlake2. The SHA-256/192 and
+// SHAKE256/192 truncated functions from NIST SP 800-208 are provided for stateful hash-based signatures.
+// Known-answer self-tests run before first use (see SelfTestSetting); a failure makes New refuse to construct hashers.
+// NewFor enforces the SP 800-57 minimum security strength of SecurityPolicy for a Purpose (e.g. DigitalSignature)
+// and each hasher's Approved() method reports the approved service indicator for audit logging.
+// Destroy() (or Sum() of a hasher passed to ZeroizeOnSum) wipes the chaining state, pending block and serialized copies
+// (message schedules are per block and wiped after each); the Go runtime may still hold transient stack or
+// encoding/json scratch copies.
+// HashAlgorithm values print, parse (ParseHashAlgorithm) and marshal by canonical name, and Info() returns
+// their OIDs, crypto.Hash, protocol identifiers, sizes and security strengths. RegisterAlgorithm adds
+// third-party Hasher implementations under new HashAlgorithm values, and Algorithms() lists all of them.
+// NewSha256 (etc.) and NewTyped return a generic TypedHasher whose Sum returns a concrete digest type.
+// SHA-256 and SHA-512 use amd64 assembly kernels where the CPU supports them (SHA extensions or AVX2), falling
+// back to the pure-Go kernels on other CPUs and architectures or with the purego tag.
+// HashMany hashes batches of independent short messages without per-message construction overhead.
+// HashFiles and HashTree hash many files on a bounded worker pool, streaming results in a deterministic order.
+// HashReader hashes an io.Reader with context cancellation, progress callbacks and a byte limit.
+// HashFile hashes a file, memory-mapping regular files on Linux and detecting files that change meanwhile.
+// NewMulti returns a MultiHasher that computes several HashAlgorithm digests of one input in a single pass.
+// HashingReader, HashingWriter and VerifyingReader (which fails with an IntegrityError) wrap io streams.
+// Verify reports a mismatch for any bad expected digest; VerifyDigest returns an error saying what was wrong.
+// ExportMidstate and NewFromMidstate save and resume SHA-2 chaining values after whole blocks (or set custom IVs).
+// NewLengthExtension and GluePadding demonstrate length-extension forgeries of H(secret || message) MACs.
 package hasher
 
 import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"reflect"
+	"time"
 )
 
 // TODO:    Create discussion.adoc document
@@ -18,11 +50,22 @@ import (
 
 // Hasher interface
 type Hasher interface {
+	Approved() bool
+	BlockSize() int
 	Copy() Hasher
+	Destroy()
 	HashAlgorithm() HashAlgorithm
 	InterimSum() interface{}
+	Len() uint64
+	Reset() Hasher
+	Size() int
 	Sum() interface{}
+	SumInto(dst []byte) []byte
+	Verify(expected []byte) bool
+	VerifyBase64(expected string) bool
+	VerifyHex(expected string) bool
 	Write(message []byte) Hasher
+	WriteBits(message []byte, bitLength uint64) Hasher
 }
 
 // HashAlgorithm is a unique type that will be enumerated
@@ -30,20 +73,56 @@ type HashAlgorithm uint32
 
 // Enumerated constant for each hasher256 algorithm
 const (
-	None       HashAlgorithm = iota
-	Sha224     HashAlgorithm = iota
-	Sha256     HashAlgorithm = iota
-	Sha384     HashAlgorithm = iota
-	Sha512     HashAlgorithm = iota
-	Sha512t224 HashAlgorithm = iota
-	Sha512t256 HashAlgorithm = iota
+	None         HashAlgorithm = iota
+	Sha224       HashAlgorithm = iota
+	Sha256       HashAlgorithm = iota
+	Sha384       HashAlgorithm = iota
+	Sha512       HashAlgorithm = iota
+	Sha512t224   HashAlgorithm = iota
+	Sha512t256   HashAlgorithm = iota
+	Ripemd160    HashAlgorithm = iota
+	Blake2b      HashAlgorithm = iota
+	Blake2s      HashAlgorithm = iota
+	Sha256t192   HashAlgorithm = iota
+	Shake256t192 HashAlgorithm = iota
 )
 
+// Blake2Parameters configures a BLAKE2b or BLAKE2s instance; the zero value gives the unkeyed maximum size
+type Blake2Parameters struct {
+	Size            int    `json:"size"`            // Digest bytes: 1..64 for BLAKE2b, 1..32 for BLAKE2s, 0 for max
+	Key             []byte `json:"key"`             // Optional MAC key: up to 64 bytes BLAKE2b, 32 bytes BLAKE2s
+	Salt            []byte `json:"salt"`            // Optional salt: up to 16 bytes BLAKE2b, 8 bytes BLAKE2s
+	Personalization []byte `json:"personalization"` // Optional: up to 16 bytes BLAKE2b, 8 bytes BLAKE2s
+}
+
 // LogFatal can be overridden to prevent fatal exits (e.g. for testing)
 var LogFatal = log.Fatal
 
-// New constructs a fresh instance of the specified HashAlgorithm
+// New constructs a fresh instance of the specified HashAlgorithm for General purpose use
 func New(hashAlgorithm HashAlgorithm) Hasher {
+	return NewFor(General, hashAlgorithm)
+}
+
+// NewFor constructs a fresh instance of the specified HashAlgorithm once the self-tests due have passed
+// and the SecurityPolicy permits it for the purpose; Approved() then reports the approved service indicator
+func NewFor(purpose Purpose, hashAlgorithm HashAlgorithm) Hasher {
+	if !selfTestGate(hashAlgorithm) {
+		LogFatal("Self-test failure: hasher is in the error state")
+		return nil
+	}
+	if err := SecurityPolicy.Check(purpose, hashAlgorithm, time.Now()); err != nil {
+		LogFatal(err)
+		return nil
+	}
+	var hasher = newHasher(hashAlgorithm)
+	if approver, ok := hasher.(approver); ok {
+		approver.approve(approvedFor(purpose, hashAlgorithm))
+	}
+	return hasher
+}
+
+// newHasher constructs a fresh instance of the specified HashAlgorithm without consulting the self-tests
+func newHasher(hashAlgorithm HashAlgorithm) Hasher {
 	switch hashAlgorithm {
 	case Sha224:
 		return new(sha224).init(Sha224)
@@ -57,14 +136,180 @@ func New(hashAlgorithm HashAlgorithm) Hasher {
 		return new(sha512t224).init(Sha512t224)
 	case Sha512t256:
 		return new(sha512t256).init(Sha512t256)
+	case Ripemd160:
+		return new(ripemd160).init(Ripemd160)
+	case Blake2b:
+		return new(blake2b).init(Blake2b)
+	case Blake2s:
+		return new(blake2s).init(Blake2s)
+	case Sha256t192:
+		return new(sha256t192).init(Sha256t192)
+	case Shake256t192:
+		return new(shake256t192).init(Shake256t192)
 	case None:
 		LogFatal("HashAlgorithm \"None\" specified")
 	default:
+		if constructor, ok := registeredConstructor(hashAlgorithm); ok {
+			return constructor()
+		}
 		LogFatal("Unknown hashAlgorithm")
 	}
 	return nil
 }
 
+// NewBlake2 constructs a fresh BLAKE2b or BLAKE2s instance with the specified size, key, salt and personalization
+func NewBlake2(hashAlgorithm HashAlgorithm, parameters Blake2Parameters) Hasher {
+	if !selfTestGate(hashAlgorithm) {
+		LogFatal("Self-test failure: hasher is in the error state")
+		return nil
+	}
+	var size = parameters.Size
+	if size == 0 {
+		size = digestBits(hashAlgorithm) / 8
+	}
+	if err := SecurityPolicy.check(General, hashAlgorithm, size*8, time.Now()); err != nil {
+		LogFatal(err)
+		return nil
+	}
+	switch hashAlgorithm {
+	case Blake2b:
+		return new(blake2b).initParameters(parameters)
+	case Blake2s:
+		return new(blake2s).initParameters(parameters)
+	default:
+		LogFatal("NewBlake2() requires hashAlgorithm Blake2b or Blake2s")
+	}
+	return nil
+}
+
+// approver is implemented by every engine so constructors can set the approved service indicator
+type approver interface {
+	approve(approved bool)
+}
+
+// zeroizer is implemented by every engine so ZeroizeOnSum can set the option on one hasher
+type zeroizer interface {
+	zeroizeOnSum(zeroize bool)
+}
+
+// ZeroizeOnSum makes this hasher (and its copies) wipe its state, as Destroy does, once Sum has computed the
+// digest; a further Sum or InterimSum before Reset is then fatal. It returns hasher for chaining.
+func ZeroizeOnSum(hasher Hasher) Hasher {
+	var engine, ok = hasher.(zeroizer)
+	if !ok {
+		LogFatal(fmt.Sprintf("ZeroizeOnSum() is not supported by %v", hasher.HashAlgorithm()))
+		return hasher
+	}
+	engine.zeroizeOnSum(true)
+	return hasher
+}
+
+// clone returns a copy of the parameters that does not share slices with the caller
+func (parameters Blake2Parameters) clone() Blake2Parameters {
+	parameters.Key = append([]byte(nil), parameters.Key...)
+	parameters.Salt = append([]byte(nil), parameters.Salt...)
+	parameters.Personalization = append([]byte(nil), parameters.Personalization...)
+	return parameters
+}
+
+// digestArray returns the digest as a byte array of matching length (so sums remain comparable with ==)
+func digestArray(digest []byte) interface{} {
+	var array = reflect.New(reflect.ArrayOf(len(digest), reflect.TypeOf(byte(0)))).Elem()
+	reflect.Copy(array, reflect.ValueOf(digest))
+	return array.Interface()
+}
+
+// digestBytes converts a digest array (as returned by Sum and InterimSum) to a byte slice
+func digestBytes(digest interface{}) []byte {
+	var value = reflect.ValueOf(digest)
+	var result = make([]byte, value.Len())
+	reflect.Copy(reflect.ValueOf(result), value)
+	return result
+}
+
+// writeAlignedBits serves WriteBits for algorithms without bit-granular input (whole bytes only)
+func writeAlignedBits(hasher Hasher, message []byte, bitLength uint64) Hasher {
+	if bitLength%8 > 0 {
+		LogFatal("WriteBits() with a partial final byte is only supported by the SHA-2 algorithms")
+		return hasher
+	}
+	if bitLength > uint64(len(message))*8 {
+		LogFatal("WriteBits() bitLength exceeds the message length")
+		return hasher
+	}
+	return hasher.Write(message[:bitLength/8])
+}
+
+// sumDestination returns the first size bytes of a SumInto destination, which must be long enough
+func sumDestination(dst []byte, size int) []byte {
+	if len(dst) < size {
+		LogFatal("SumInto() destination is shorter than the digest")
+		return nil
+	}
+	return dst[:size]
+}
+
+// ErrDigestLength matches (with errors.Is) the error VerifyDigest and VerifyingReader return for an expected
+// digest of the wrong length
+var ErrDigestLength = errors.New("hasher: expected digest has the wrong length")
+
+// VerifyDigest finalizes hasher and compares its digest with expected in constant time, returning nil on a
+// match and an IntegrityError otherwise. An expected digest of the wrong length (typically the digest of a
+// different HashAlgorithm) gives an error wrapping ErrDigestLength that names the likely HashAlgorithm.
+func VerifyDigest(hasher Hasher, expected []byte) error {
+	var digest = hasher.SumInto(make([]byte, hasher.Size()))
+	if len(expected) != len(digest) {
+		zeroizeBytes(digest)
+		return digestLengthError(hasher, len(expected))
+	}
+	if subtle.ConstantTimeCompare(digest, expected) == 1 {
+		zeroizeBytes(digest)
+		return nil
+	}
+	return &IntegrityError{HashAlgorithm: hasher.HashAlgorithm(), Expected: append([]byte(nil), expected...),
+		Actual: digest}
+}
+
+// digestLengthError describes an expected digest of length bytes that hasher can never produce
+func digestLengthError(hasher Hasher, length int) error {
+	var likely []HashAlgorithm
+	for _, hashAlgorithm := range Algorithms() {
+		if digestBits(hashAlgorithm) == length*8 {
+			likely = append(likely, hashAlgorithm)
+		}
+	}
+	return fmt.Errorf("%w: %d bytes (HashAlgorithm %v?) but %v produces %d bytes", ErrDigestLength, length, likely,
+		hasher.HashAlgorithm(), hasher.Size())
+}
+
+// verify finalizes hasher and compares its digest with expected in constant time; expected digests are often
+// untrusted input, so one of the wrong length is simply a mismatch (VerifyDigest says why)
+func verify(hasher Hasher, expected []byte) bool {
+	var digest = hasher.SumInto(make([]byte, hasher.Size()))
+	defer zeroizeBytes(digest)
+	return len(expected) == len(digest) && subtle.ConstantTimeCompare(digest, expected) == 1
+}
+
+// verifyBase64 decodes the expected digest for verify (invalid base64 is a mismatch)
+func verifyBase64(hasher Hasher, expected string) bool {
+	decoded, err := base64.StdEncoding.DecodeString(expected)
+	if err != nil {
+		zeroizeBytes(hasher.SumInto(make([]byte, hasher.Size()))) // Finalized regardless, as Verify promises
+		return false
+	}
+	return verify(hasher, decoded)
+}
+
+// verifyHex decodes the expected digest for verify (invalid hex is a mismatch)
+func verifyHex(hasher Hasher, expected string) bool {
+	decoded, err := hex.DecodeString(expected)
+	if err != nil {
+		zeroizeBytes(hasher.SumInto(make([]byte, hasher.Size()))) // Finalized regardless, as Verify promises
+		return false
+	}
+	return verify(hasher, decoded)
+}
+
 // hasherCopy deep copy via marshall the src then unmarshall into dst (independent of HashAlgorithm)
 func hasherCopy(dst Hasher, src Hasher) Hasher {
 	originalData, err := json.Marshal(&src)
@@ -72,8 +317,20 @@ func hasherCopy(dst Hasher, src Hasher) Hasher {
 		LogFatal("hasherCopy() unable to serialize source")
 	}
 	err = json.Unmarshal(originalData, &dst)
+	zeroizeBytes(originalData) // The serialized state holds the chaining values and pending message bytes
+	inspectCopyBuffer(originalData)
 	if err != nil {
 		LogFatal("hasherCopy() unable to deserialize destimation")
 	}
 	return dst
 }
+
+// inspectCopyBuffer lets the tests examine the serialized state after hasherCopy has wiped it
+var inspectCopyBuffer = func(buffer []byte) {}
+
+// zeroizeBytes overwrites a buffer that held sensitive data
+func zeroizeBytes(buffer []byte) {
+	for index := range buffer {
+		buffer[index] = 0
+	}
+}
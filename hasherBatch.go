@@ -0,0 +1,59 @@
+package hasher
+
+import (
+	"runtime"
+	"sync"
+)
+
+// Messages per goroutine below which HashMany stays on the calling goroutine
+const hashManyMinimum = 256
+
+// HashMany hashes each of messages independently with hashAlgorithm and returns the digests in input order.
+// Each goroutine reuses one hasher (Reset rather than New) and the digests share one backing array, so the
+// per-message cost is the hashing itself; large batches are spread over up to GOMAXPROCS goroutines.
+func HashMany(hashAlgorithm HashAlgorithm, messages [][]byte) [][]byte {
+	var hasher = New(hashAlgorithm)
+	if hasher == nil {
+		return nil
+	}
+	var size = hasher.Size()
+	var digests = make([][]byte, len(messages))
+	var backing = make([]byte, len(messages)*size)
+	for i := range digests {
+		digests[i] = backing[i*size : (i+1)*size : (i+1)*size]
+	}
+
+	var workers = runtime.GOMAXPROCS(0)
+	if limit := len(messages) / hashManyMinimum; limit < workers {
+		workers = limit
+	}
+	if workers <= 1 {
+		hashManyRange(hasher, messages, digests)
+		return digests
+	}
+	var group sync.WaitGroup
+	var chunk = (len(messages) + workers - 1) / workers
+	for start := 0; start < len(messages); start += chunk {
+		var end = start + chunk
+		if end > len(messages) {
+			end = len(messages)
+		}
+		if start > 0 {
+			hasher = New(hashAlgorithm)
+		}
+		group.Add(1)
+		go func(hasher Hasher, start int, end int) {
+			defer group.Done()
+			hashManyRange(hasher, messages[start:end], digests[start:end])
+		}(hasher, start, end)
+	}
+	group.Wait()
+	return digests
+}
+
+// hashManyRange hashes each message into the digest at the same index, reusing hasher
+func hashManyRange(hasher Hasher, messages [][]byte, digests [][]byte) {
+	for i, message := range messages {
+		hasher.Reset().Write(message).SumInto(digests[i])
+	}
+}
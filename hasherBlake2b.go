@@ -0,0 +1,327 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Structure for BLAKE2b based algorithms
+type hasher2b struct {
+	ApprovedService bool             `json:"approvedService"`
+	Counter         uint64           `json:"counter"`
+	FillLine        int              `json:"fillLine"`
+	Finished        bool             `json:"finished"`
+	HashBlock2b     *[8]uint64       `json:"hashBlock2b"`
+	LenProcessed    uint64           `json:"lenProcessed"`
+	Parameters      Blake2Parameters `json:"parameters"`
+	TempBlock2b     *[128]byte       `json:"tempBlock2b"`
+	Zeroized        bool             `json:"zeroized"`
+	ZeroizeOnSum    bool             `json:"zeroizeOnSum"`
+}
+
+// Structure personalized for blake2b
+type blake2b struct {
+	hasher2b `json:"hasher2b"`
+}
+
+const (
+	bYTESINBLOCK2B   int = 128
+	mAXBYTESINSIZE2B int = 64
+	mAXBYTESINSALT2B int = 16
+)
+
+// Initialization vector (shared with SHA-512)
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+// Message word permutations (shared with BLAKE2s); round i uses blake2Sigma[i%10]
+var blake2Sigma = [10][16]int{
+	{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15},
+	{14, 10, 4, 8, 9, 15, 13, 6, 1, 12, 0, 2, 11, 7, 5, 3},
+	{11, 8, 12, 0, 5, 2, 15, 13, 10, 14, 3, 6, 7, 1, 9, 4},
+	{7, 9, 3, 1, 13, 12, 11, 14, 2, 6, 5, 10, 4, 0, 15, 8},
+	{9, 0, 5, 7, 2, 4, 10, 15, 14, 1, 11, 12, 6, 8, 3, 13},
+	{2, 12, 6, 10, 0, 11, 8, 3, 4, 13, 7, 5, 15, 14, 1, 9},
+	{12, 5, 1, 15, 14, 13, 4, 10, 0, 7, 6, 3, 9, 2, 8, 11},
+	{13, 11, 7, 14, 12, 1, 3, 9, 5, 0, 15, 4, 8, 6, 2, 10},
+	{6, 15, 14, 9, 11, 3, 0, 8, 12, 2, 13, 7, 1, 4, 10, 5},
+	{10, 2, 8, 4, 7, 6, 1, 5, 15, 11, 9, 14, 3, 12, 13, 0},
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose)
+func (hasher *blake2b) Approved() bool {
+	return hasher.ApprovedService
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *blake2b) BlockSize() int {
+	return bYTESINBLOCK2B
+}
+
+// Copy returns a deep copy
+func (hasher *blake2b) Copy() Hasher {
+	return hasherCopy(newHasher(Blake2b), hasher)
+}
+
+// Destroy zeroizes the chaining state and pending block (and key); the hasher cannot be used again until Reset
+func (hasher *blake2b) Destroy() {
+	zeroize2b(&hasher.hasher2b)
+}
+
+// HashAlgorithm returns the hash algorithm of the "object"
+func (hasher *blake2b) HashAlgorithm() HashAlgorithm {
+	return Blake2b
+}
+
+// InterimSum returns "the sum so far" without finalizing the original hasher
+func (hasher blake2b) InterimSum() interface{} {
+	return hasher.Copy().Sum()
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *blake2b) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Reset returns the hasher to its parameterized IV (without reallocating) so it can be reused, even after Sum
+func (hasher *blake2b) Reset() Hasher {
+	if hasher.Zeroized && len(hasher.Parameters.Key) > 0 {
+		LogFatal("Reset() of a keyed BLAKE2b hasher after its key was zeroized")
+	}
+	reset2b(&hasher.hasher2b)
+	return hasher
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *blake2b) Size() int {
+	return hasher.Parameters.Size
+}
+
+// Sum returns the final sum and marks the hasher as finished to prevent additional writes
+func (hasher *blake2b) Sum() interface{} {
+	var digest [mAXBYTESINSIZE2B]byte
+	sum2b(&hasher.hasher2b, digest[:hasher.Parameters.Size])
+	return digestArray(digest[:hasher.Parameters.Size])
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *blake2b) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, hasher.Parameters.Size); dst != nil {
+		sum2b(&hasher.hasher2b, dst)
+	}
+	return dst
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *blake2b) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *blake2b) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *blake2b) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// Write pushes additional data into the hasher; can be called multiple times in streaming applications
+func (hasher *blake2b) Write(message []byte) Hasher {
+	write2b(&hasher.hasher2b, message)
+	return hasher
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; only whole bytes are supported
+func (hasher *blake2b) WriteBits(message []byte, bitLength uint64) Hasher {
+	return writeAlignedBits(hasher, message, bitLength)
+}
+
+// init creates an initialized structure specific to the algorithm in play
+func (hasher *blake2b) init(hashAlgorithm HashAlgorithm) Hasher {
+	return hasher.initParameters(Blake2Parameters{})
+}
+
+// initParameters creates an initialized structure with the specified size, key, salt and personalization
+func (hasher *blake2b) initParameters(parameters Blake2Parameters) Hasher {
+	if parameters.Size == 0 {
+		parameters.Size = mAXBYTESINSIZE2B
+	}
+	if parameters.Size < 1 || parameters.Size > mAXBYTESINSIZE2B {
+		LogFatal("BLAKE2b digest size must be between 1 and 64 bytes")
+	}
+	if len(parameters.Key) > mAXBYTESINSIZE2B {
+		LogFatal("BLAKE2b key must not exceed 64 bytes")
+	}
+	if len(parameters.Salt) > mAXBYTESINSALT2B || len(parameters.Personalization) > mAXBYTESINSALT2B {
+		LogFatal("BLAKE2b salt and personalization must not exceed 16 bytes")
+	}
+	hasher.Parameters = parameters.clone()
+	hasher.TempBlock2b = &[128]byte{0}
+	hasher.HashBlock2b = &[8]uint64{}
+	return hasher.Reset()
+}
+
+// approve sets the approved service indicator
+func (hasher *hasher2b) approve(approved bool) {
+	hasher.ApprovedService = approved
+}
+
+// zeroizeOnSum sets whether Sum wipes the state once the digest has been computed
+func (hasher *hasher2b) zeroizeOnSum(zeroize bool) {
+	hasher.ZeroizeOnSum = zeroize
+}
+
+// reset2b returns the engine to the parameterized IV (and pending key block) without reallocating
+func reset2b(hasher *hasher2b) {
+	*hasher.HashBlock2b = blake2bIV
+	*hasher.TempBlock2b = [128]byte{}
+	hasher.Counter = 0
+	hasher.FillLine = 0
+	hasher.Finished = false
+	hasher.LenProcessed = 0
+	hasher.Zeroized = false
+
+	// Fold the parameter block (size, key length, fanout=1, depth=1, salt, personalization) into h[0:7]
+	var salt, personalization [mAXBYTESINSALT2B]byte
+	copy(salt[:], hasher.Parameters.Salt)
+	copy(personalization[:], hasher.Parameters.Personalization)
+	hasher.HashBlock2b[0] ^= 0x01010000 ^ uint64(len(hasher.Parameters.Key))<<8 ^ uint64(hasher.Parameters.Size)
+	hasher.HashBlock2b[4] ^= binary.LittleEndian.Uint64(salt[0:8])
+	hasher.HashBlock2b[5] ^= binary.LittleEndian.Uint64(salt[8:16])
+	hasher.HashBlock2b[6] ^= binary.LittleEndian.Uint64(personalization[0:8])
+	hasher.HashBlock2b[7] ^= binary.LittleEndian.Uint64(personalization[8:16])
+
+	// Keyed mode: the zero-padded key is the first (full) block, compressed once more data arrives
+	if len(hasher.Parameters.Key) > 0 {
+		copy(hasher.TempBlock2b[:], hasher.Parameters.Key)
+		hasher.FillLine = bYTESINBLOCK2B
+	}
+}
+
+// zeroize2b wipes all message dependent state and marks the hasher finished and zeroized
+func zeroize2b(hasher *hasher2b) {
+	*hasher.HashBlock2b = [8]uint64{}
+	*hasher.TempBlock2b = [128]byte{}
+	hasher.Counter = 0
+	zeroizeBytes(hasher.Parameters.Key) // Salt and personalization are not secret and survive for Reset
+	hasher.FillLine = 0
+	hasher.LenProcessed = 0
+	hasher.Finished = true
+	hasher.Zeroized = true
+}
+
+// write2b does the real work of message ingestion
+func write2b(hasher *hasher2b, message []byte) {
+	if hasher.Finished {
+		LogFatal("Cannot call Write() after Sum() because the hasher has been finalized")
+	}
+	if hasher.LenProcessed+uint64(len(message)) < hasher.LenProcessed {
+		LogFatal("Total message length of 2**64 has been exceeded")
+	}
+	hasher.LenProcessed += uint64(len(message))
+
+	for len(message) > 0 {
+		// Only compress a full tempBlock once more data arrives, as the last block is flagged
+		if hasher.FillLine == bYTESINBLOCK2B {
+			hasher.Counter += uint64(bYTESINBLOCK2B)
+			oneBlock2b(hasher, hasher.TempBlock2b[:], false)
+			hasher.FillLine = 0
+		}
+		var length = copy(hasher.TempBlock2b[hasher.FillLine:], message)
+		hasher.FillLine += length
+		message = message[length:]
+	}
+}
+
+// sum2b finalizes (once) and writes the leading len(digest) bytes of the hash value into digest
+func sum2b(hasher *hasher2b, digest []byte) {
+	if hasher.Zeroized {
+		LogFatal("Sum() after the hasher state was zeroized")
+	}
+	if !hasher.Finished {
+		finalize2b(hasher)
+	}
+	hasher.Finished = true
+	var block [mAXBYTESINSIZE2B]byte
+	for index := 0; index < mAXBYTESINSIZE2B; index += 8 {
+		binary.LittleEndian.PutUint64(block[index:index+8], hasher.HashBlock2b[index/8])
+	}
+	copy(digest, block[:])
+	block = [mAXBYTESINSIZE2B]byte{}
+	if hasher.ZeroizeOnSum {
+		zeroize2b(hasher)
+	}
+}
+
+// finalize2b finishes the calculation by zero padding and hashing the flagged final block
+func finalize2b(hasher *hasher2b) {
+	hasher.Counter += uint64(hasher.FillLine)
+	for index := hasher.FillLine; index < bYTESINBLOCK2B; index++ {
+		hasher.TempBlock2b[index] = 0x00
+	}
+	oneBlock2b(hasher, hasher.TempBlock2b[:], true)
+
+	// Clear working data
+	hasher.FillLine = 0
+	for index := range hasher.TempBlock2b {
+		hasher.TempBlock2b[index] = 0x00
+	}
+}
+
+// oneBlock2b does one full compression function iteration
+func oneBlock2b(hasher *hasher2b, message []byte, final bool) {
+	// The message words and working vector are per call and wiped before returning
+	var m, v [16]uint64
+	defer wipeWords2b(&m)
+	defer wipeWords2b(&v)
+	for i := 0; i < 16; i++ {
+		m[i] = binary.LittleEndian.Uint64(message[i*8 : i*8+8])
+	}
+
+	// Initialize working vector from the chaining value, IV, counter and final flag
+	copy(v[0:8], hasher.HashBlock2b[:])
+	copy(v[8:16], blake2bIV[:])
+	v[12] ^= hasher.Counter
+	if final {
+		v[14] = ^v[14]
+	}
+
+	for round := 0; round < 12; round++ {
+		s := &blake2Sigma[round%10]
+		g2b(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		g2b(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		g2b(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		g2b(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		g2b(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		g2b(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		g2b(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		g2b(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := 0; i < 8; i++ {
+		hasher.HashBlock2b[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// g2b is the BLAKE2b mixing function
+func g2b(v *[16]uint64, a int, b int, c int, d int, x uint64, y uint64) {
+	v[a] = v[a] + v[b] + x
+	v[d] = bits.RotateLeft64(v[d]^v[a], -32)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -24)
+	v[a] = v[a] + v[b] + y
+	v[d] = bits.RotateLeft64(v[d]^v[a], -16)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft64(v[b]^v[c], -63)
+}
+
+// wipeWords2b overwrites message words or a working vector (not inlined, so the stores cannot be optimized away)
+//
+//go:noinline
+func wipeWords2b(words *[16]uint64) {
+	*words = [16]uint64{}
+}
@@ -0,0 +1,313 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Structure for BLAKE2s based algorithms
+type hasher2s struct {
+	ApprovedService bool             `json:"approvedService"`
+	Counter         uint64           `json:"counter"`
+	FillLine        int              `json:"fillLine"`
+	Finished        bool             `json:"finished"`
+	HashBlock2s     *[8]uint32       `json:"hashBlock2s"`
+	LenProcessed    uint64           `json:"lenProcessed"`
+	Parameters      Blake2Parameters `json:"parameters"`
+	TempBlock2s     *[64]byte        `json:"tempBlock2s"`
+	Zeroized        bool             `json:"zeroized"`
+	ZeroizeOnSum    bool             `json:"zeroizeOnSum"`
+}
+
+// Structure personalized for blake2s
+type blake2s struct {
+	hasher2s `json:"hasher2s"`
+}
+
+const (
+	bYTESINBLOCK2S   int = 64
+	mAXBYTESINSIZE2S int = 32
+	mAXBYTESINSALT2S int = 8
+)
+
+// Initialization vector (shared with SHA-256)
+var blake2sIV = [8]uint32{
+	0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose)
+func (hasher *blake2s) Approved() bool {
+	return hasher.ApprovedService
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *blake2s) BlockSize() int {
+	return bYTESINBLOCK2S
+}
+
+// Copy returns a deep copy
+func (hasher *blake2s) Copy() Hasher {
+	return hasherCopy(newHasher(Blake2s), hasher)
+}
+
+// Destroy zeroizes the chaining state and pending block (and key); the hasher cannot be used again until Reset
+func (hasher *blake2s) Destroy() {
+	zeroize2s(&hasher.hasher2s)
+}
+
+// HashAlgorithm returns the hash algorithm of the "object"
+func (hasher *blake2s) HashAlgorithm() HashAlgorithm {
+	return Blake2s
+}
+
+// InterimSum returns "the sum so far" without finalizing the original hasher
+func (hasher blake2s) InterimSum() interface{} {
+	return hasher.Copy().Sum()
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *blake2s) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Reset returns the hasher to its parameterized IV (without reallocating) so it can be reused, even after Sum
+func (hasher *blake2s) Reset() Hasher {
+	if hasher.Zeroized && len(hasher.Parameters.Key) > 0 {
+		LogFatal("Reset() of a keyed BLAKE2s hasher after its key was zeroized")
+	}
+	reset2s(&hasher.hasher2s)
+	return hasher
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *blake2s) Size() int {
+	return hasher.Parameters.Size
+}
+
+// Sum returns the final sum and marks the hasher as finished to prevent additional writes
+func (hasher *blake2s) Sum() interface{} {
+	var digest [mAXBYTESINSIZE2S]byte
+	sum2s(&hasher.hasher2s, digest[:hasher.Parameters.Size])
+	return digestArray(digest[:hasher.Parameters.Size])
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *blake2s) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, hasher.Parameters.Size); dst != nil {
+		sum2s(&hasher.hasher2s, dst)
+	}
+	return dst
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *blake2s) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *blake2s) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *blake2s) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// Write pushes additional data into the hasher; can be called multiple times in streaming applications
+func (hasher *blake2s) Write(message []byte) Hasher {
+	write2s(&hasher.hasher2s, message)
+	return hasher
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; only whole bytes are supported
+func (hasher *blake2s) WriteBits(message []byte, bitLength uint64) Hasher {
+	return writeAlignedBits(hasher, message, bitLength)
+}
+
+// init creates an initialized structure specific to the algorithm in play
+func (hasher *blake2s) init(hashAlgorithm HashAlgorithm) Hasher {
+	return hasher.initParameters(Blake2Parameters{})
+}
+
+// initParameters creates an initialized structure with the specified size, key, salt and personalization
+func (hasher *blake2s) initParameters(parameters Blake2Parameters) Hasher {
+	if parameters.Size == 0 {
+		parameters.Size = mAXBYTESINSIZE2S
+	}
+	if parameters.Size < 1 || parameters.Size > mAXBYTESINSIZE2S {
+		LogFatal("BLAKE2s digest size must be between 1 and 32 bytes")
+	}
+	if len(parameters.Key) > mAXBYTESINSIZE2S {
+		LogFatal("BLAKE2s key must not exceed 32 bytes")
+	}
+	if len(parameters.Salt) > mAXBYTESINSALT2S || len(parameters.Personalization) > mAXBYTESINSALT2S {
+		LogFatal("BLAKE2s salt and personalization must not exceed 8 bytes")
+	}
+	hasher.Parameters = parameters.clone()
+	hasher.TempBlock2s = &[64]byte{0}
+	hasher.HashBlock2s = &[8]uint32{}
+	return hasher.Reset()
+}
+
+// approve sets the approved service indicator
+func (hasher *hasher2s) approve(approved bool) {
+	hasher.ApprovedService = approved
+}
+
+// zeroizeOnSum sets whether Sum wipes the state once the digest has been computed
+func (hasher *hasher2s) zeroizeOnSum(zeroize bool) {
+	hasher.ZeroizeOnSum = zeroize
+}
+
+// reset2s returns the engine to the parameterized IV (and pending key block) without reallocating
+func reset2s(hasher *hasher2s) {
+	*hasher.HashBlock2s = blake2sIV
+	*hasher.TempBlock2s = [64]byte{}
+	hasher.Counter = 0
+	hasher.FillLine = 0
+	hasher.Finished = false
+	hasher.LenProcessed = 0
+	hasher.Zeroized = false
+
+	// Fold the parameter block (size, key length, fanout=1, depth=1, salt, personalization) into h[0:7]
+	var salt, personalization [mAXBYTESINSALT2S]byte
+	copy(salt[:], hasher.Parameters.Salt)
+	copy(personalization[:], hasher.Parameters.Personalization)
+	hasher.HashBlock2s[0] ^= 0x01010000 ^ uint32(len(hasher.Parameters.Key))<<8 ^ uint32(hasher.Parameters.Size)
+	hasher.HashBlock2s[4] ^= binary.LittleEndian.Uint32(salt[0:4])
+	hasher.HashBlock2s[5] ^= binary.LittleEndian.Uint32(salt[4:8])
+	hasher.HashBlock2s[6] ^= binary.LittleEndian.Uint32(personalization[0:4])
+	hasher.HashBlock2s[7] ^= binary.LittleEndian.Uint32(personalization[4:8])
+
+	// Keyed mode: the zero-padded key is the first (full) block, compressed once more data arrives
+	if len(hasher.Parameters.Key) > 0 {
+		copy(hasher.TempBlock2s[:], hasher.Parameters.Key)
+		hasher.FillLine = bYTESINBLOCK2S
+	}
+}
+
+// zeroize2s wipes all message dependent state and marks the hasher finished and zeroized
+func zeroize2s(hasher *hasher2s) {
+	*hasher.HashBlock2s = [8]uint32{}
+	*hasher.TempBlock2s = [64]byte{}
+	hasher.Counter = 0
+	zeroizeBytes(hasher.Parameters.Key) // Salt and personalization are not secret and survive for Reset
+	hasher.FillLine = 0
+	hasher.LenProcessed = 0
+	hasher.Finished = true
+	hasher.Zeroized = true
+}
+
+// write2s does the real work of message ingestion
+func write2s(hasher *hasher2s, message []byte) {
+	if hasher.Finished {
+		LogFatal("Cannot call Write() after Sum() because the hasher has been finalized")
+	}
+	if hasher.LenProcessed+uint64(len(message)) < hasher.LenProcessed {
+		LogFatal("Total message length of 2**64 has been exceeded")
+	}
+	hasher.LenProcessed += uint64(len(message))
+
+	for len(message) > 0 {
+		// Only compress a full tempBlock once more data arrives, as the last block is flagged
+		if hasher.FillLine == bYTESINBLOCK2S {
+			hasher.Counter += uint64(bYTESINBLOCK2S)
+			oneBlock2s(hasher, hasher.TempBlock2s[:], false)
+			hasher.FillLine = 0
+		}
+		var length = copy(hasher.TempBlock2s[hasher.FillLine:], message)
+		hasher.FillLine += length
+		message = message[length:]
+	}
+}
+
+// sum2s finalizes (once) and writes the leading len(digest) bytes of the hash value into digest
+func sum2s(hasher *hasher2s, digest []byte) {
+	if hasher.Zeroized {
+		LogFatal("Sum() after the hasher state was zeroized")
+	}
+	if !hasher.Finished {
+		finalize2s(hasher)
+	}
+	hasher.Finished = true
+	var block [mAXBYTESINSIZE2S]byte
+	for index := 0; index < mAXBYTESINSIZE2S; index += 4 {
+		binary.LittleEndian.PutUint32(block[index:index+4], hasher.HashBlock2s[index/4])
+	}
+	copy(digest, block[:])
+	block = [mAXBYTESINSIZE2S]byte{}
+	if hasher.ZeroizeOnSum {
+		zeroize2s(hasher)
+	}
+}
+
+// finalize2s finishes the calculation by zero padding and hashing the flagged final block
+func finalize2s(hasher *hasher2s) {
+	hasher.Counter += uint64(hasher.FillLine)
+	for index := hasher.FillLine; index < bYTESINBLOCK2S; index++ {
+		hasher.TempBlock2s[index] = 0x00
+	}
+	oneBlock2s(hasher, hasher.TempBlock2s[:], true)
+
+	// Clear working data
+	hasher.FillLine = 0
+	for index := range hasher.TempBlock2s {
+		hasher.TempBlock2s[index] = 0x00
+	}
+}
+
+// oneBlock2s does one full compression function iteration
+func oneBlock2s(hasher *hasher2s, message []byte, final bool) {
+	// The message words and working vector are per call and wiped before returning
+	var m, v [16]uint32
+	defer wipeWords2s(&m)
+	defer wipeWords2s(&v)
+	for i := 0; i < 16; i++ {
+		m[i] = binary.LittleEndian.Uint32(message[i*4 : i*4+4])
+	}
+
+	// Initialize working vector from the chaining value, IV, counter and final flag
+	copy(v[0:8], hasher.HashBlock2s[:])
+	copy(v[8:16], blake2sIV[:])
+	v[12] ^= uint32(hasher.Counter)
+	v[13] ^= uint32(hasher.Counter >> 32)
+	if final {
+		v[14] = ^v[14]
+	}
+
+	for round := 0; round < 10; round++ {
+		s := &blake2Sigma[round]
+		g2s(&v, 0, 4, 8, 12, m[s[0]], m[s[1]])
+		g2s(&v, 1, 5, 9, 13, m[s[2]], m[s[3]])
+		g2s(&v, 2, 6, 10, 14, m[s[4]], m[s[5]])
+		g2s(&v, 3, 7, 11, 15, m[s[6]], m[s[7]])
+		g2s(&v, 0, 5, 10, 15, m[s[8]], m[s[9]])
+		g2s(&v, 1, 6, 11, 12, m[s[10]], m[s[11]])
+		g2s(&v, 2, 7, 8, 13, m[s[12]], m[s[13]])
+		g2s(&v, 3, 4, 9, 14, m[s[14]], m[s[15]])
+	}
+
+	for i := 0; i < 8; i++ {
+		hasher.HashBlock2s[i] ^= v[i] ^ v[i+8]
+	}
+}
+
+// g2s is the BLAKE2s mixing function
+func g2s(v *[16]uint32, a int, b int, c int, d int, x uint32, y uint32) {
+	v[a] = v[a] + v[b] + x
+	v[d] = bits.RotateLeft32(v[d]^v[a], -16)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -12)
+	v[a] = v[a] + v[b] + y
+	v[d] = bits.RotateLeft32(v[d]^v[a], -8)
+	v[c] = v[c] + v[d]
+	v[b] = bits.RotateLeft32(v[b]^v[c], -7)
+}
+
+// wipeWords2s overwrites message words or a working vector (not inlined, so the stores cannot be optimized away)
+//
+//go:noinline
+func wipeWords2s(words *[16]uint32) {
+	*words = [16]uint32{}
+}
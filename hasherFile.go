@@ -0,0 +1,120 @@
+package hasher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+)
+
+// ErrFileChanged is returned when a file changes size or modification time while it is being hashed
+var ErrFileChanged = errors.New("hasher: file changed while it was being hashed")
+
+// Bytes of a mapping written per Write call (bounds how long a fault can go unnoticed, not the speed)
+const mappedChunkSize = 4 << 20
+
+// HashFile hashes the file at path with hashAlgorithm and returns the digest and the number of bytes hashed.
+// Regular files are memory-mapped where the platform supports it (Linux) and the mapping is fed straight to
+// the whole-block path of Write; pipes, devices and other platforms are read through a buffer.
+func HashFile(hashAlgorithm HashAlgorithm, path string) ([]byte, uint64, error) {
+	var hasher = New(hashAlgorithm)
+	if hasher == nil {
+		return nil, 0, fmt.Errorf("hasher: cannot construct %v", hashAlgorithm)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer file.Close()
+	if err := hashFileInto(file, []Hasher{hasher}, nil); err != nil {
+		return nil, hasher.Len(), err
+	}
+	return hasher.SumInto(make([]byte, hasher.Size())), hasher.Len(), nil
+}
+
+// hashFileInto writes the contents of file to every hasher, through a memory mapping where possible and
+// otherwise through buffer (allocated if nil); a regular file that changes meanwhile gives ErrFileChanged
+func hashFileInto(file *os.File, hashers []Hasher, buffer []byte) error {
+	before, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	var regular = before.Mode().IsRegular()
+	var hashed int64
+	var data, unmap, mapped = []byte(nil), func() {}, false
+	if regular && mapFiles {
+		data, unmap, mapped = mapFile(file, before.Size())
+	}
+	if mapped {
+		err = writeMapped(file, data, hashers)
+		unmap()
+		hashed = int64(len(data))
+	} else {
+		if buffer == nil {
+			buffer = make([]byte, fileBufferSize)
+		}
+		hashed, err = writeBuffered(file, buffer, hashers)
+	}
+	// Pipes and devices have no size to check, nor do procfs and sysfs files, whose reported size (0, or a
+	// page) is unrelated to their content
+	if err != nil || !regular || before.Size() == 0 || pseudoFile(file) {
+		return err
+	}
+	after, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if hashed != before.Size() || after.Size() != before.Size() || !after.ModTime().Equal(before.ModTime()) {
+		return ErrFileChanged
+	}
+	return nil
+}
+
+// mapFiles memory-maps regular files where the platform can (the tests clear it to force buffered reads)
+var mapFiles = true
+
+// writeMapped writes a mapping to every hasher; a truncated file faults on access (SIGBUS), which is turned
+// into ErrFileChanged rather than a crash
+func writeMapped(file *os.File, data []byte, hashers []Hasher) (err error) {
+	defer debug.SetPanicOnFault(debug.SetPanicOnFault(true))
+	defer func() {
+		if r := recover(); r != nil {
+			if _, fault := r.(interface{ Addr() uintptr }); !fault {
+				panic(r)
+			}
+			err = ErrFileChanged
+		}
+	}()
+	afterStart(file)
+	for ; len(data) > 0; data = data[min(len(data), mappedChunkSize):] {
+		for _, hasher := range hashers {
+			hasher.Write(data[:min(len(data), mappedChunkSize)])
+		}
+	}
+	return nil
+}
+
+// afterStart lets the tests change a file once hashing has started (after mapping it, or after the first read)
+var afterStart = func(file *os.File) {}
+
+// writeBuffered reads file to EOF through buffer, writing each read to every hasher, and returns the bytes read
+func writeBuffered(file *os.File, buffer []byte, hashers []Hasher) (int64, error) {
+	var hashed int64
+	for {
+		var length, err = file.Read(buffer)
+		for _, hasher := range hashers {
+			hasher.Write(buffer[:length])
+		}
+		if hashed == 0 {
+			afterStart(file)
+		}
+		hashed += int64(length)
+		if err == io.EOF {
+			return hashed, nil
+		}
+		if err != nil {
+			return hashed, err
+		}
+	}
+}
@@ -0,0 +1,36 @@
+//go:build linux
+
+package hasher
+
+import (
+	"os"
+	"syscall"
+)
+
+// mapFile maps size bytes of file read-only for sequential access; ok is false if it cannot be mapped
+func mapFile(file *os.File, size int64) (data []byte, unmap func(), ok bool) {
+	if size <= 0 || int64(int(size)) != size {
+		return nil, nil, false
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, false
+	}
+	syscall.Madvise(data, syscall.MADV_SEQUENTIAL)
+	return data, func() { syscall.Munmap(data) }, true
+}
+
+// Filesystem magic numbers of procfs and sysfs (statfs f_type)
+const (
+	procSuperMagic = 0x9fa0
+	sysfsMagic     = 0x62656572
+)
+
+// pseudoFile reports whether file lives on procfs or sysfs, whose files report a size unrelated to their content
+func pseudoFile(file *os.File) bool {
+	var stat syscall.Statfs_t
+	if err := syscall.Fstatfs(int(file.Fd()), &stat); err != nil {
+		return false
+	}
+	return int64(stat.Type) == procSuperMagic || int64(stat.Type) == sysfsMagic
+}
@@ -0,0 +1,15 @@
+//go:build !linux
+
+package hasher
+
+import "os"
+
+// mapFile does not map files on this platform, so they are read through a buffer
+func mapFile(file *os.File, size int64) (data []byte, unmap func(), ok bool) {
+	return nil, nil, false
+}
+
+// pseudoFile reports no procfs or sysfs files on this platform
+func pseudoFile(file *os.File) bool {
+	return false
+}
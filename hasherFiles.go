@@ -0,0 +1,174 @@
+package hasher
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// FileDigest is the result of hashing one file; Err is set (and Digests nil) when the file could not be hashed
+type FileDigest struct {
+	Path    string                   `json:"path"`
+	Size    int64                    `json:"size"`
+	Digests map[HashAlgorithm][]byte `json:"digests"`
+	Err     error                    `json:"-"`
+}
+
+// FileOptions configures HashFiles and HashTree
+type FileOptions struct {
+	Algorithms []HashAlgorithm // The algorithms applied to every file (Sha256 if empty)
+	Workers    int             // The number of files hashed concurrently (GOMAXPROCS if zero)
+}
+
+// Size of the read buffer of each worker
+const fileBufferSize = 64 * 1024
+
+// A file to hash and the slot its result goes to
+type fileJob struct {
+	path   string
+	result chan FileDigest
+}
+
+// HashFiles hashes the files at paths on a bounded worker pool and streams the results in the order of paths.
+// A file that cannot be hashed yields a FileDigest with Err set rather than ending the run. The channel is
+// closed after the last result, or early if ctx is cancelled (once files already being hashed are finished).
+func HashFiles(ctx context.Context, paths []string, options FileOptions) <-chan FileDigest {
+	return hashPaths(ctx, options, func(visit func(path string, err error) bool) {
+		for _, path := range paths {
+			if !visit(path, nil) {
+				return
+			}
+		}
+	})
+}
+
+// HashTree hashes every regular file below root (in the lexical order of filepath.WalkDir) like HashFiles;
+// directories that cannot be read are reported as a FileDigest with Err set and the walk continues.
+func HashTree(ctx context.Context, root string, options FileOptions) <-chan FileDigest {
+	return hashPaths(ctx, options, func(visit func(path string, err error) bool) {
+		filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				if !visit(path, err) {
+					return filepath.SkipAll
+				}
+				if entry != nil && entry.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.Type().IsRegular() && !visit(path, nil) {
+				return filepath.SkipAll
+			}
+			return nil
+		})
+	})
+}
+
+// hashPaths runs the worker pool over the paths produced by walk; each path gets a result slot queued in walk
+// order, so the results can be emitted in that order however the workers finish
+func hashPaths(ctx context.Context, options FileOptions, walk func(visit func(path string, err error) bool)) <-chan FileDigest {
+	var algorithms = options.Algorithms
+	if len(algorithms) == 0 {
+		algorithms = []HashAlgorithm{Sha256}
+	}
+	var workers = options.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var jobs = make(chan fileJob)
+	var slots = make(chan chan FileDigest, 4*workers) // Bounds how far the walk runs ahead of the output
+	var output = make(chan FileDigest, workers)
+	var group sync.WaitGroup // Output closes only after the workers stop, so no hashing outlives the stream
+
+	for i := 0; i < workers; i++ {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			var buffer = make([]byte, fileBufferSize)
+			for job := range jobs {
+				job.result <- hashFile(job.path, algorithms, buffer)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		defer close(slots)
+		walk(func(path string, err error) bool {
+			var slot = make(chan FileDigest, 1) // Buffered: workers never wait for the output
+			select {
+			case slots <- slot:
+			case <-ctx.Done():
+				return false
+			}
+			if err != nil {
+				slot <- FileDigest{Path: path, Err: err}
+				return true
+			}
+			select {
+			case jobs <- fileJob{path: path, result: slot}:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+	}()
+
+	go func() {
+		defer close(output)
+		defer group.Wait()
+		for slot := range slots {
+			select {
+			case result := <-slot:
+				select {
+				case output <- result:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return output
+}
+
+// hashFile hashes one regular file with every algorithm, mapping it or reading through buffer
+func hashFile(path string, algorithms []HashAlgorithm, buffer []byte) FileDigest {
+	var result = FileDigest{Path: path}
+	file, err := os.Open(path)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer file.Close()
+	if info, err := file.Stat(); err != nil {
+		result.Err = err
+		return result
+	} else if !info.Mode().IsRegular() {
+		result.Err = fmt.Errorf("hasher: %v is not a regular file", path)
+		return result
+	}
+
+	var hashers = make([]Hasher, len(algorithms))
+	for i, hashAlgorithm := range algorithms {
+		if hashers[i] = New(hashAlgorithm); hashers[i] == nil {
+			result.Err = fmt.Errorf("hasher: cannot construct %v", hashAlgorithm)
+			return result
+		}
+	}
+	if err := hashFileInto(file, hashers, buffer); err != nil {
+		result.Err = err
+		return result
+	}
+	result.Size = int64(hashers[0].Len())
+	result.Digests = make(map[HashAlgorithm][]byte, len(algorithms))
+	for i, hashAlgorithm := range algorithms {
+		result.Digests[hashAlgorithm] = hashers[i].SumInto(make([]byte, hashers[i].Size()))
+	}
+	return result
+}
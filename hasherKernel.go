@@ -0,0 +1,68 @@
+package hasher
+
+// The SHA-2 compression kernels: the pure-Go oneBlock256 and oneBlock512 are the reference, and where the CPU
+// supports it an assembly kernel (hasherKernel_amd64.s) is selected at init instead.
+// Building with the purego tag keeps the pure-Go kernels on every architecture.
+
+// kernel256 and kernel512 hash whole blocks of message into state
+type (
+	kernel256 func(state *[8]uint32, message []byte)
+	kernel512 func(state *[8]uint64, message []byte)
+)
+
+// block256Kernel and block512Kernel are the assembly kernels in use, the first the CPU supports that reproduces
+// the reference; nil (which the tests also set to force the reference) selects the pure-Go kernels
+var (
+	block256Kernel = agreeing256(cpuKernels256())
+	block512Kernel = agreeing512(cpuKernels512())
+)
+
+// agreeing256 returns the first of kernels that reproduces the reference on two blocks, or nil if none does
+func agreeing256(kernels []kernel256) kernel256 {
+	for _, kernel := range kernels {
+		if kernelAgrees256(kernel) {
+			return kernel
+		}
+	}
+	return nil
+}
+
+// agreeing512 returns the first of kernels that reproduces the reference on two blocks, or nil if none does
+func agreeing512(kernels []kernel512) kernel512 {
+	for _, kernel := range kernels {
+		if kernelAgrees512(kernel) {
+			return kernel
+		}
+	}
+	return nil
+}
+
+// kernelAgrees256 checks an assembly kernel reproduces the reference on two blocks before it is enabled
+func kernelAgrees256(kernel kernel256) bool {
+	var message [2 * bYTESINBLOCK256]byte
+	for i := range message {
+		message[i] = byte(i * 7)
+	}
+	var reference = hasher256{HashBlock256: &[8]uint32{}}
+	copy(reference.HashBlock256[:], sha256Constants[:])
+	var state = *reference.HashBlock256
+	oneBlock256(&reference, message[:bYTESINBLOCK256])
+	oneBlock256(&reference, message[bYTESINBLOCK256:])
+	kernel(&state, message[:])
+	return state == *reference.HashBlock256
+}
+
+// kernelAgrees512 checks an assembly kernel reproduces the reference on two blocks before it is enabled
+func kernelAgrees512(kernel kernel512) bool {
+	var message [2 * bYTESINBLOCK512]byte
+	for i := range message {
+		message[i] = byte(i * 7)
+	}
+	var reference = hasher512{HashBlock512: &[8]uint64{}}
+	copy(reference.HashBlock512[:], sha512Constants[:])
+	var state = *reference.HashBlock512
+	oneBlock512(&reference, message[:bYTESINBLOCK512])
+	oneBlock512(&reference, message[bYTESINBLOCK512:])
+	kernel(&state, message[:])
+	return state == *reference.HashBlock512
+}
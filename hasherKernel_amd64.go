@@ -0,0 +1,66 @@
+//go:build amd64 && !purego
+
+package hasher
+
+// SHA-256 uses the SHA extensions (with SSSE3/SSE4.1 shuffles), or else an AVX message schedule with BMI2
+// scalar rounds; SHA-512 uses an AVX2 message schedule with BMI2 scalar rounds
+var shaExtensions, avx2 = cpuFeatures()
+
+// cpuFeatures reports whether the CPU supports the SHA extensions kernel and (with the OS saving the AVX
+// register state) the AVX2 kernels
+func cpuFeatures() (shaKernel bool, avx2Kernel bool) {
+	if maxLeaf, _, _, _ := cpuid(0, 0); maxLeaf < 7 {
+		return false, false
+	}
+	_, _, ecx1, _ := cpuid(1, 0)
+	_, ebx7, _, _ := cpuid(7, 0)
+	var ssse3, sse41, osxsave, avx = ecx1&(1<<9) != 0, ecx1&(1<<19) != 0, ecx1&(1<<27) != 0, ecx1&(1<<28) != 0
+	var avx2, bmi2, sha = ebx7&(1<<5) != 0, ebx7&(1<<8) != 0, ebx7&(1<<29) != 0
+	var ymmState bool
+	if osxsave {
+		xcr0, _ := xgetbv()
+		ymmState = xcr0&6 == 6
+	}
+	return sha && ssse3 && sse41, avx && avx2 && bmi2 && ymmState
+}
+
+// cpuKernels256 lists the SHA-256 kernels this CPU supports, preferred first
+func cpuKernels256() []kernel256 {
+	var kernels []kernel256
+	if shaExtensions {
+		kernels = append(kernels, func(state *[8]uint32, message []byte) {
+			block256SHA(state, message, &sha256Constants)
+		})
+	}
+	if avx2 {
+		kernels = append(kernels, func(state *[8]uint32, message []byte) {
+			block256AVX2(state, message, &sha256Constants)
+		})
+	}
+	return kernels
+}
+
+// cpuKernels512 lists the SHA-512 kernels this CPU supports, preferred first
+func cpuKernels512() []kernel512 {
+	if !avx2 {
+		return nil
+	}
+	return []kernel512{func(state *[8]uint64, message []byte) {
+		block512AVX2(state, message, sha512Constants)
+	}}
+}
+
+//go:noescape
+func block256SHA(state *[8]uint32, message []byte, constants *[64]uint32)
+
+//go:noescape
+func block256AVX2(state *[8]uint32, message []byte, constants *[64]uint32)
+
+//go:noescape
+func block512AVX2(state *[8]uint64, message []byte, constants *[80]uint64)
+
+//go:noescape
+func cpuid(leaf uint32, subleaf uint32) (eax uint32, ebx uint32, ecx uint32, edx uint32)
+
+//go:noescape
+func xgetbv() (eax uint32, edx uint32)
@@ -0,0 +1,500 @@
+//go:build amd64 && !purego
+
+#include "textflag.h"
+
+// SHA-256 with the SHA extensions. The state is kept as ABEF/CDGH in X1/X2, the message words of the last
+// four groups rotate through X3-X6, and X0 carries message+constants to SHA256RNDS2 (an implicit operand).
+
+// Four rounds on the message group m with the constants at offset k
+#define ROUNDS256(m, k) \
+	MOVO        m, X0; \
+	PADDD       k(AX), X0; \
+	SHA256RNDS2 X0, X1, X2; \
+	PSHUFD      $0x0e, X0, X0; \
+	SHA256RNDS2 X0, X2, X1
+
+// The next message group into m0 from the previous four groups m0 (oldest) to m3 (newest)
+#define SCHEDULE256(m0, m1, m2, m3) \
+	SHA256MSG1 m1, m0; \
+	MOVO       m3, X7; \
+	PALIGNR    $4, m2, X7; \
+	PADDD      X7, m0; \
+	SHA256MSG2 m3, m0
+
+// func block256SHA(state *[8]uint32, message []byte, constants *[64]uint32)
+TEXT ·block256SHA(SB), NOSPLIT, $0-40
+	MOVQ state+0(FP), DI
+	MOVQ message_base+8(FP), SI
+	MOVQ message_len+16(FP), DX
+	MOVQ constants+32(FP), AX
+	SHRQ $6, DX
+	JZ   done256
+	SHLQ $6, DX
+	ADDQ SI, DX
+
+	MOVOU   shuffle256<>(SB), X8
+	MOVOU   0(DI), X1           // DCBA
+	MOVOU   16(DI), X2          // HGFE
+	PSHUFD  $0xb1, X1, X1       // CDAB
+	PSHUFD  $0x1b, X2, X2       // EFGH
+	MOVO    X1, X7
+	PALIGNR $8, X2, X1          // ABEF
+	PBLENDW $0xf0, X7, X2       // CDGH
+
+block256:
+	MOVO   X1, X9
+	MOVO   X2, X10
+	MOVOU  0(SI), X3
+	PSHUFB X8, X3
+	MOVOU  16(SI), X4
+	PSHUFB X8, X4
+	MOVOU  32(SI), X5
+	PSHUFB X8, X5
+	MOVOU  48(SI), X6
+	PSHUFB X8, X6
+
+	ROUNDS256(X3, 0)
+	ROUNDS256(X4, 16)
+	ROUNDS256(X5, 32)
+	ROUNDS256(X6, 48)
+	SCHEDULE256(X3, X4, X5, X6)
+	ROUNDS256(X3, 64)
+	SCHEDULE256(X4, X5, X6, X3)
+	ROUNDS256(X4, 80)
+	SCHEDULE256(X5, X6, X3, X4)
+	ROUNDS256(X5, 96)
+	SCHEDULE256(X6, X3, X4, X5)
+	ROUNDS256(X6, 112)
+	SCHEDULE256(X3, X4, X5, X6)
+	ROUNDS256(X3, 128)
+	SCHEDULE256(X4, X5, X6, X3)
+	ROUNDS256(X4, 144)
+	SCHEDULE256(X5, X6, X3, X4)
+	ROUNDS256(X5, 160)
+	SCHEDULE256(X6, X3, X4, X5)
+	ROUNDS256(X6, 176)
+	SCHEDULE256(X3, X4, X5, X6)
+	ROUNDS256(X3, 192)
+	SCHEDULE256(X4, X5, X6, X3)
+	ROUNDS256(X4, 208)
+	SCHEDULE256(X5, X6, X3, X4)
+	ROUNDS256(X5, 224)
+	SCHEDULE256(X6, X3, X4, X5)
+	ROUNDS256(X6, 240)
+
+	PADDD X9, X1
+	PADDD X10, X2
+	ADDQ  $64, SI
+	CMPQ  SI, DX
+	JB    block256
+
+	PSHUFD  $0x1b, X1, X1       // FEBA
+	PSHUFD  $0xb1, X2, X2       // DCHG
+	MOVO    X1, X7
+	PBLENDW $0xf0, X2, X1       // DCBA
+	PALIGNR $8, X7, X2          // HGFE
+	MOVOU   X1, 0(DI)
+	MOVOU   X2, 16(DI)
+
+	// Leave no message words behind in the vector registers
+	PXOR X0, X0
+	PXOR X3, X3
+	PXOR X4, X4
+	PXOR X5, X5
+	PXOR X6, X6
+	PXOR X7, X7
+
+done256:
+	RET
+
+// Byte order shuffle of four big-endian 32 bit words
+DATA shuffle256<>+0(SB)/8, $0x0405060700010203
+DATA shuffle256<>+8(SB)/8, $0x0c0d0e0f08090a0b
+GLOBL shuffle256<>(SB), RODATA|NOPTR, $16
+
+// SHA-256 with AVX and BMI2, for CPUs without the SHA extensions. The frame holds the message schedule W[0..63]
+// at 0(SP) and W+K at 256(SP), then the message pointer and end at 512(SP) and 520(SP), which frees SI and DI
+// for the rounds. Each group of four rounds also computes the schedule four words ahead, so the vector unit
+// works alongside the scalar rounds. The working variables a-h live in R8-R15 and are renamed by the macro
+// arguments rather than moved.
+
+// sigma0 of the words in x, via t (ror 7 ^ ror 18 ^ shr 3; the shift halves of a rotate never overlap)
+#define SIGMA0_256(x, t, r) \
+	VPSRLD $7, x, r; \
+	VPSLLD $25, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLD $18, x, t; \
+	VPXOR  t, r, r; \
+	VPSLLD $14, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLD $3, x, t; \
+	VPXOR  t, r, r
+
+// sigma1 of the words in x, via t (ror 17 ^ ror 19 ^ shr 10)
+#define SIGMA1_256(x, t, r) \
+	VPSRLD $17, x, r; \
+	VPSLLD $15, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLD $19, x, t; \
+	VPXOR  t, r, r; \
+	VPSLLD $13, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLD $10, x, t; \
+	VPXOR  t, r, r
+
+// W[t+16..t+19] and their W+K, where W[t] is at off(SP)(CX*1); the second pair of words depends on the first,
+// so its sigma1 is done after it, on the first pair shifted into the upper half (sigma1 of zero is zero)
+#define SCHEDULE256AVX(off) \
+	VMOVDQU off+4(SP)(CX*1), X1; \
+	SIGMA0_256(X1, X3, X2); \
+	VPADDD  off+0(SP)(CX*1), X2, X0; \
+	VPADDD  off+36(SP)(CX*1), X0, X0; \
+	VMOVQ   off+56(SP)(CX*1), X1; \
+	SIGMA1_256(X1, X3, X2); \
+	VPADDD  X2, X0, X0; \
+	VPSLLDQ $8, X0, X1; \
+	SIGMA1_256(X1, X3, X2); \
+	VPADDD  X2, X0, X0; \
+	VMOVDQU X0, off+64(SP)(CX*1); \
+	MOVQ    constants+32(FP), DX; \
+	VPADDD  off+64(DX)(CX*1), X0, X0; \
+	VMOVDQU X0, off+320(SP)(CX*1)
+
+// One round; h becomes the new a and d the new e, with W+K taken from off(SP)(CX*1). Maj(a, b, c) is
+// ((a^b) & (b^c)) ^ b, where y holds b^c on entry and x receives a^b, the next round's b^c.
+#define ROUND256AVX(a, b, c, d, e, f, g, h, off, x, y) \
+	ADDL  off(SP)(CX*1), h; \
+	RORXL $6, e, AX; \
+	RORXL $11, e, BX; \
+	XORL  BX, AX; \
+	RORXL $25, e, BX; \
+	XORL  BX, AX; \
+	MOVL  f, DX; \
+	XORL  g, DX; \
+	ANDL  e, DX; \
+	XORL  g, DX; \
+	ADDL  AX, h; \
+	ADDL  DX, h; \
+	ADDL  h, d; \
+	RORXL $2, a, AX; \
+	RORXL $13, a, BX; \
+	XORL  BX, AX; \
+	RORXL $22, a, BX; \
+	XORL  BX, AX; \
+	MOVL  a, x; \
+	XORL  b, x; \
+	ANDL  x, y; \
+	XORL  b, y; \
+	ADDL  AX, h; \
+	ADDL  y, h
+
+// Eight rounds on the W+K at 256(SP)(CX*1), starting with y = b^c in DI
+#define ROUNDS256AVX(sched0, sched1) \
+	sched0; \
+	ROUND256AVX(R8, R9, R10, R11, R12, R13, R14, R15, 256, SI, DI); \
+	ROUND256AVX(R15, R8, R9, R10, R11, R12, R13, R14, 260, DI, SI); \
+	ROUND256AVX(R14, R15, R8, R9, R10, R11, R12, R13, 264, SI, DI); \
+	ROUND256AVX(R13, R14, R15, R8, R9, R10, R11, R12, 268, DI, SI); \
+	sched1; \
+	ROUND256AVX(R12, R13, R14, R15, R8, R9, R10, R11, 272, SI, DI); \
+	ROUND256AVX(R11, R12, R13, R14, R15, R8, R9, R10, 276, DI, SI); \
+	ROUND256AVX(R10, R11, R12, R13, R14, R15, R8, R9, 280, SI, DI); \
+	ROUND256AVX(R9, R10, R11, R12, R13, R14, R15, R8, 284, DI, SI)
+
+// func block256AVX2(state *[8]uint32, message []byte, constants *[64]uint32)
+TEXT ·block256AVX2(SB), 0, $528-40
+	MOVQ message_base+8(FP), SI
+	MOVQ message_len+16(FP), DI
+	SHRQ $6, DI
+	JZ   done256AVX2
+	SHLQ $6, DI
+	ADDQ SI, DI
+	MOVQ SI, 512(SP)
+	MOVQ DI, 520(SP)
+	VMOVDQU shuffle256<>(SB), X9
+
+block256AVX2:
+	MOVQ    512(SP), SI
+	MOVQ    constants+32(FP), DX
+	VMOVDQU 0(SI), X0
+	VPSHUFB X9, X0, X0
+	VMOVDQU X0, 0(SP)
+	VPADDD  0(DX), X0, X0
+	VMOVDQU X0, 256(SP)
+	VMOVDQU 16(SI), X0
+	VPSHUFB X9, X0, X0
+	VMOVDQU X0, 16(SP)
+	VPADDD  16(DX), X0, X0
+	VMOVDQU X0, 272(SP)
+	VMOVDQU 32(SI), X0
+	VPSHUFB X9, X0, X0
+	VMOVDQU X0, 32(SP)
+	VPADDD  32(DX), X0, X0
+	VMOVDQU X0, 288(SP)
+	VMOVDQU 48(SI), X0
+	VPSHUFB X9, X0, X0
+	VMOVDQU X0, 48(SP)
+	VPADDD  48(DX), X0, X0
+	VMOVDQU X0, 304(SP)
+
+	MOVQ state+0(FP), DX
+	MOVL 0(DX), R8
+	MOVL 4(DX), R9
+	MOVL 8(DX), R10
+	MOVL 12(DX), R11
+	MOVL 16(DX), R12
+	MOVL 20(DX), R13
+	MOVL 24(DX), R14
+	MOVL 28(DX), R15
+	MOVL R9, DI
+	XORL R10, DI
+	XORQ CX, CX
+
+rounds256AVX2: // Rounds 0..47, scheduling W[16..63]
+	ROUNDS256AVX(SCHEDULE256AVX(0), SCHEDULE256AVX(16))
+	ADDQ $32, CX
+	CMPQ CX, $192
+	JB   rounds256AVX2
+
+last256AVX2: // Rounds 48..63
+	ROUNDS256AVX(NOP, NOP)
+	ADDQ $32, CX
+	CMPQ CX, $256
+	JB   last256AVX2
+
+	MOVQ state+0(FP), DX
+	ADDL R8, 0(DX)
+	ADDL R9, 4(DX)
+	ADDL R10, 8(DX)
+	ADDL R11, 12(DX)
+	ADDL R12, 16(DX)
+	ADDL R13, 20(DX)
+	ADDL R14, 24(DX)
+	ADDL R15, 28(DX)
+	MOVQ 512(SP), SI
+	ADDQ $64, SI
+	MOVQ SI, 512(SP)
+	CMPQ SI, 520(SP)
+	JB   block256AVX2
+
+	// Leave no message schedule behind on the stack
+	VPXOR X0, X0, X0
+	XORQ  CX, CX
+
+wipe256AVX2:
+	VMOVDQU X0, 0(SP)(CX*1)
+	ADDQ    $16, CX
+	CMPQ    CX, $512
+	JB      wipe256AVX2
+	VPXOR   X1, X1, X1
+	VPXOR   X2, X2, X2
+	VPXOR   X3, X3, X3
+
+done256AVX2:
+	RET
+
+// SHA-512 with AVX2 and BMI2, laid out as the SHA-256 kernel above: W[0..79] at 0(SP), W+K at 640(SP), the
+// message pointer and end at 1280(SP) and 1288(SP), and the schedule computed four words ahead of the rounds.
+
+// sigma0 of the words in x, via t (ror 1 ^ ror 8 ^ shr 7; the shift halves of a rotate never overlap)
+#define SIGMA0(x, t, r) \
+	VPSRLQ $1, x, r; \
+	VPSLLQ $63, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLQ $8, x, t; \
+	VPXOR  t, r, r; \
+	VPSLLQ $56, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLQ $7, x, t; \
+	VPXOR  t, r, r
+
+// sigma1 of the words in x, via t (ror 19 ^ ror 61 ^ shr 6)
+#define SIGMA1(x, t, r) \
+	VPSRLQ $19, x, r; \
+	VPSLLQ $45, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLQ $61, x, t; \
+	VPXOR  t, r, r; \
+	VPSLLQ $3, x, t; \
+	VPXOR  t, r, r; \
+	VPSRLQ $6, x, t; \
+	VPXOR  t, r, r
+
+// W[t+16..t+19] and their W+K, where W[t] is at off(SP)(CX*1); the second pair of words depends on the first,
+// so it is done after it in the upper lane
+#define SCHEDULE512(off) \
+	VMOVDQU      off+8(SP)(CX*1), Y1; \
+	SIGMA0(Y1, Y3, Y2); \
+	VPADDQ       off+0(SP)(CX*1), Y2, Y0; \
+	VPADDQ       off+72(SP)(CX*1), Y0, Y0; \
+	VMOVDQU      off+112(SP)(CX*1), X1; \
+	SIGMA1(X1, X3, X2); \
+	VPADDQ       X2, X0, X1; \
+	SIGMA1(X1, X3, X2); \
+	VEXTRACTI128 $1, Y0, X0; \
+	VPADDQ       X2, X0, X0; \
+	VINSERTI128  $1, X0, Y1, Y1; \
+	VMOVDQU      Y1, off+128(SP)(CX*1); \
+	MOVQ         constants+32(FP), DX; \
+	VPADDQ       off+128(DX)(CX*1), Y1, Y1; \
+	VMOVDQU      Y1, off+768(SP)(CX*1)
+
+// One round; h becomes the new a and d the new e, with W+K taken from off(SP)(CX*1). Maj(a, b, c) is
+// ((a^b) & (b^c)) ^ b, where y holds b^c on entry and x receives a^b, the next round's b^c.
+#define ROUND512(a, b, c, d, e, f, g, h, off, x, y) \
+	ADDQ  off(SP)(CX*1), h; \
+	RORXQ $14, e, AX; \
+	RORXQ $18, e, BX; \
+	XORQ  BX, AX; \
+	RORXQ $41, e, BX; \
+	XORQ  BX, AX; \
+	MOVQ  f, DX; \
+	XORQ  g, DX; \
+	ANDQ  e, DX; \
+	XORQ  g, DX; \
+	ADDQ  AX, h; \
+	ADDQ  DX, h; \
+	ADDQ  h, d; \
+	RORXQ $28, a, AX; \
+	RORXQ $34, a, BX; \
+	XORQ  BX, AX; \
+	RORXQ $39, a, BX; \
+	XORQ  BX, AX; \
+	MOVQ  a, x; \
+	XORQ  b, x; \
+	ANDQ  x, y; \
+	XORQ  b, y; \
+	ADDQ  AX, h; \
+	ADDQ  y, h
+
+// Eight rounds on the W+K at 640(SP)(CX*1), starting with y = b^c in DI
+#define ROUNDS512(sched0, sched1) \
+	sched0; \
+	ROUND512(R8, R9, R10, R11, R12, R13, R14, R15, 640, SI, DI); \
+	ROUND512(R15, R8, R9, R10, R11, R12, R13, R14, 648, DI, SI); \
+	ROUND512(R14, R15, R8, R9, R10, R11, R12, R13, 656, SI, DI); \
+	ROUND512(R13, R14, R15, R8, R9, R10, R11, R12, 664, DI, SI); \
+	sched1; \
+	ROUND512(R12, R13, R14, R15, R8, R9, R10, R11, 672, SI, DI); \
+	ROUND512(R11, R12, R13, R14, R15, R8, R9, R10, 680, DI, SI); \
+	ROUND512(R10, R11, R12, R13, R14, R15, R8, R9, 688, SI, DI); \
+	ROUND512(R9, R10, R11, R12, R13, R14, R15, R8, 696, DI, SI)
+
+// func block512AVX2(state *[8]uint64, message []byte, constants *[80]uint64)
+TEXT ·block512AVX2(SB), 0, $1296-40
+	MOVQ message_base+8(FP), SI
+	MOVQ message_len+16(FP), DI
+	SHRQ $7, DI
+	JZ   done512
+	SHLQ $7, DI
+	ADDQ SI, DI
+	MOVQ SI, 1280(SP)
+	MOVQ DI, 1288(SP)
+	VMOVDQU shuffle512<>(SB), Y9
+
+block512:
+	MOVQ    1280(SP), SI
+	MOVQ    constants+32(FP), DX
+	VMOVDQU 0(SI), Y0
+	VPSHUFB Y9, Y0, Y0
+	VMOVDQU Y0, 0(SP)
+	VPADDQ  0(DX), Y0, Y0
+	VMOVDQU Y0, 640(SP)
+	VMOVDQU 32(SI), Y0
+	VPSHUFB Y9, Y0, Y0
+	VMOVDQU Y0, 32(SP)
+	VPADDQ  32(DX), Y0, Y0
+	VMOVDQU Y0, 672(SP)
+	VMOVDQU 64(SI), Y0
+	VPSHUFB Y9, Y0, Y0
+	VMOVDQU Y0, 64(SP)
+	VPADDQ  64(DX), Y0, Y0
+	VMOVDQU Y0, 704(SP)
+	VMOVDQU 96(SI), Y0
+	VPSHUFB Y9, Y0, Y0
+	VMOVDQU Y0, 96(SP)
+	VPADDQ  96(DX), Y0, Y0
+	VMOVDQU Y0, 736(SP)
+
+	MOVQ state+0(FP), DX
+	MOVQ 0(DX), R8
+	MOVQ 8(DX), R9
+	MOVQ 16(DX), R10
+	MOVQ 24(DX), R11
+	MOVQ 32(DX), R12
+	MOVQ 40(DX), R13
+	MOVQ 48(DX), R14
+	MOVQ 56(DX), R15
+	MOVQ R9, DI
+	XORQ R10, DI
+	XORQ CX, CX
+
+rounds512: // Rounds 0..63, scheduling W[16..79]
+	ROUNDS512(SCHEDULE512(0), SCHEDULE512(32))
+	ADDQ $64, CX
+	CMPQ CX, $512
+	JB   rounds512
+
+last512: // Rounds 64..79
+	ROUNDS512(NOP, NOP)
+	ADDQ $64, CX
+	CMPQ CX, $640
+	JB   last512
+
+	MOVQ state+0(FP), DX
+	ADDQ R8, 0(DX)
+	ADDQ R9, 8(DX)
+	ADDQ R10, 16(DX)
+	ADDQ R11, 24(DX)
+	ADDQ R12, 32(DX)
+	ADDQ R13, 40(DX)
+	ADDQ R14, 48(DX)
+	ADDQ R15, 56(DX)
+	MOVQ 1280(SP), SI
+	ADDQ $128, SI
+	MOVQ SI, 1280(SP)
+	CMPQ SI, 1288(SP)
+	JB   block512
+
+	// Leave no message schedule behind on the stack
+	VPXOR Y0, Y0, Y0
+	XORQ  CX, CX
+
+wipe512:
+	VMOVDQU Y0, 0(SP)(CX*1)
+	ADDQ    $32, CX
+	CMPQ    CX, $1280
+	JB      wipe512
+	VPXOR   Y1, Y1, Y1
+	VPXOR   Y2, Y2, Y2
+	VPXOR   Y3, Y3, Y3
+
+done512:
+	VZEROUPPER
+	RET
+
+// Byte order shuffle of four big-endian 64 bit words (VPSHUFB works within each 128 bit lane)
+DATA shuffle512<>+0(SB)/8, $0x0001020304050607
+DATA shuffle512<>+8(SB)/8, $0x08090a0b0c0d0e0f
+DATA shuffle512<>+16(SB)/8, $0x0001020304050607
+DATA shuffle512<>+24(SB)/8, $0x08090a0b0c0d0e0f
+GLOBL shuffle512<>(SB), RODATA|NOPTR, $32
+
+// func cpuid(leaf uint32, subleaf uint32) (eax uint32, ebx uint32, ecx uint32, edx uint32)
+TEXT ·cpuid(SB), NOSPLIT, $0-24
+	MOVL leaf+0(FP), AX
+	MOVL subleaf+4(FP), CX
+	CPUID
+	MOVL AX, eax+8(FP)
+	MOVL BX, ebx+12(FP)
+	MOVL CX, ecx+16(FP)
+	MOVL DX, edx+20(FP)
+	RET
+
+// func xgetbv() (eax uint32, edx uint32)
+TEXT ·xgetbv(SB), NOSPLIT, $0-8
+	MOVL $0, CX
+	XGETBV
+	MOVL AX, eax+0(FP)
+	MOVL DX, edx+4(FP)
+	RET
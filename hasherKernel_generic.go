@@ -0,0 +1,13 @@
+//go:build !amd64 || purego
+
+package hasher
+
+// cpuKernels256 lists no SHA-256 kernels: there is no assembly on this architecture (or with the purego tag)
+func cpuKernels256() []kernel256 {
+	return nil
+}
+
+// cpuKernels512 lists no SHA-512 kernels: there is no assembly on this architecture (or with the purego tag)
+func cpuKernels512() []kernel512 {
+	return nil
+}
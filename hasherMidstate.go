@@ -0,0 +1,102 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Midstate is the chaining value of a SHA-2 family hasher after a whole number of blocks; NewFromMidstate
+// resumes from it (e.g. after a fixed prefix such as a BIP-340 tag or an HMAC key block), or starts from it
+// as a custom IV when Length is zero
+type Midstate struct {
+	HashAlgorithm HashAlgorithm `json:"hashAlgorithm"`
+	ChainingValue []byte        `json:"chainingValue"` // HashBlock256 (32 bytes) or HashBlock512 (64 bytes), big-endian
+	Length        uint64        `json:"length"`        // Bytes hashed so far; a multiple of the block size
+}
+
+// midstater is implemented by the SHA-2 engines, whose state between blocks is just the chaining value
+type midstater interface {
+	midstate() (chainingValue []byte, length uint64, aligned bool)
+	setMidstate(chainingValue []byte, length uint64)
+}
+
+// ExportMidstate returns the midstate of a SHA-2 family hasher; only whole blocks may have been written
+func ExportMidstate(hasher Hasher) Midstate {
+	var engine, ok = hasher.(midstater)
+	if !ok {
+		LogFatal("ExportMidstate() is only supported by the SHA-2 algorithms")
+		return Midstate{}
+	}
+	var chainingValue, length, aligned = engine.midstate()
+	if !aligned {
+		LogFatal("ExportMidstate() requires a whole number of blocks to have been written (and no Sum)")
+		return Midstate{}
+	}
+	return Midstate{HashAlgorithm: hasher.HashAlgorithm(), ChainingValue: chainingValue, Length: length}
+}
+
+// NewFromMidstate constructs a hasher positioned after midstate.Length bytes with the given chaining value.
+// Nothing shows an imported chaining value came from an approved computation (it may be a custom IV or the
+// digest a length extension starts from), so the hasher is not an approved service until Reset returns it to
+// the algorithm's standard IV.
+func NewFromMidstate(midstate Midstate) Hasher {
+	var hasher = New(midstate.HashAlgorithm)
+	if hasher == nil {
+		return nil
+	}
+	var engine, ok = hasher.(midstater)
+	if !ok {
+		LogFatal("NewFromMidstate() is only supported by the SHA-2 algorithms")
+		return nil
+	}
+	var iv, _, _ = engine.midstate()
+	if len(midstate.ChainingValue) != len(iv) {
+		LogFatal(fmt.Sprintf("NewFromMidstate() %v requires a %v byte chaining value, got %v bytes",
+			midstate.HashAlgorithm, len(iv), len(midstate.ChainingValue)))
+		return nil
+	}
+	if midstate.Length%uint64(hasher.BlockSize()) != 0 {
+		LogFatal(fmt.Sprintf("NewFromMidstate() length %v is not a multiple of the %v byte block size",
+			midstate.Length, hasher.BlockSize()))
+		return nil
+	}
+	engine.setMidstate(midstate.ChainingValue, midstate.Length)
+	return hasher
+}
+
+// GluePadding returns the FIPS 180-4 padding a SHA-2 family hasher appends to a message of length bytes
+// (0x80, zeros, and the bit length in the last 8 or 16 bytes of the block)
+func GluePadding(hashAlgorithm HashAlgorithm, length uint64) []byte {
+	var blockSize, lengthSize int
+	switch hashAlgorithm {
+	case Sha224, Sha256, Sha256t192:
+		blockSize, lengthSize = bYTESINBLOCK256, 8
+	case Sha384, Sha512, Sha512t224, Sha512t256:
+		blockSize, lengthSize = bYTESINBLOCK512, 16
+	default:
+		LogFatal("GluePadding() is only supported by the SHA-2 algorithms")
+		return nil
+	}
+	var zeros = (2*blockSize - int(length%uint64(blockSize)) - 1 - lengthSize) % blockSize
+	var padding = make([]byte, 1+zeros+lengthSize)
+	padding[0] = 0x80
+	binary.BigEndian.PutUint64(padding[len(padding)-8:], length<<3)
+	if lengthSize == 16 {
+		binary.BigEndian.PutUint64(padding[len(padding)-16:], length>>61)
+	}
+	return padding
+}
+
+// NewLengthExtension constructs a hasher positioned after an unknown message of length bytes plus its glue
+// padding, given only the digest of that message: writing an extension and calling Sum gives the digest of
+// message || GluePadding(length) || extension. This is the length-extension attack on H(secret || message)
+// MACs, for testing and teaching; only SHA-256 and SHA-512 are supported since the truncated algorithms do
+// not publish their whole chaining value.
+func NewLengthExtension(hashAlgorithm HashAlgorithm, digest []byte, length uint64) Hasher {
+	if hashAlgorithm != Sha256 && hashAlgorithm != Sha512 {
+		LogFatal("NewLengthExtension() is only supported by SHA-256 and SHA-512")
+		return nil
+	}
+	return NewFromMidstate(Midstate{HashAlgorithm: hashAlgorithm, ChainingValue: digest,
+		Length: length + uint64(len(GluePadding(hashAlgorithm, length)))})
+}
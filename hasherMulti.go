@@ -0,0 +1,126 @@
+package hasher
+
+import "sync"
+
+// Writes at least this long are fanned out to one goroutine per algorithm by a parallel MultiHasher
+const parallelWriteMinimum = 64 * 1024
+
+// MultiHasher hashes one input with several HashAlgorithm values in a single pass (e.g. the SHA-256, SHA-384
+// and SHA-512 of a release artifact) through the same fluent Write/Sum/Copy/InterimSum interface as Hasher
+type MultiHasher struct {
+	algorithms []HashAlgorithm
+	hashers    []Hasher
+	parallel   bool
+}
+
+// NewMulti constructs a MultiHasher for hashAlgorithms; at least one, and no duplicates
+func NewMulti(hashAlgorithms ...HashAlgorithm) *MultiHasher {
+	if len(hashAlgorithms) == 0 {
+		LogFatal("NewMulti() requires at least one hashAlgorithm")
+		return nil
+	}
+	var multi = &MultiHasher{algorithms: append([]HashAlgorithm(nil), hashAlgorithms...)}
+	for i, hashAlgorithm := range hashAlgorithms {
+		for _, previous := range hashAlgorithms[:i] {
+			if previous == hashAlgorithm {
+				LogFatal("NewMulti() duplicate hashAlgorithm " + hashAlgorithm.String())
+				return nil
+			}
+		}
+		var hasher = New(hashAlgorithm)
+		if hasher == nil {
+			return nil
+		}
+		multi.hashers = append(multi.hashers, hasher)
+	}
+	return multi
+}
+
+// Algorithms returns the HashAlgorithm values of the MultiHasher in construction order
+func (multi *MultiHasher) Algorithms() []HashAlgorithm {
+	return append([]HashAlgorithm(nil), multi.algorithms...)
+}
+
+// Copy returns a deep copy
+func (multi *MultiHasher) Copy() *MultiHasher {
+	var duplicate = &MultiHasher{algorithms: multi.Algorithms(), parallel: multi.parallel}
+	for _, hasher := range multi.hashers {
+		duplicate.hashers = append(duplicate.hashers, hasher.Copy())
+	}
+	return duplicate
+}
+
+// Hasher returns the underlying Hasher of hashAlgorithm (nil if it is not one of the MultiHasher's)
+func (multi *MultiHasher) Hasher(hashAlgorithm HashAlgorithm) Hasher {
+	for i, algorithm := range multi.algorithms {
+		if algorithm == hashAlgorithm {
+			return multi.hashers[i]
+		}
+	}
+	return nil
+}
+
+// InterimSum returns "the sums so far" without finalizing the original hashers
+func (multi *MultiHasher) InterimSum() map[HashAlgorithm][]byte {
+	var result = make(map[HashAlgorithm][]byte, len(multi.hashers))
+	for i, hasher := range multi.hashers {
+		result[multi.algorithms[i]] = digestBytes(hasher.InterimSum())
+	}
+	return result
+}
+
+// Len returns the number of bytes written so far
+func (multi *MultiHasher) Len() uint64 {
+	return multi.hashers[0].Len()
+}
+
+// Parallel selects whether long writes are hashed with one goroutine per algorithm
+func (multi *MultiHasher) Parallel(parallel bool) *MultiHasher {
+	multi.parallel = parallel
+	return multi
+}
+
+// Reset returns every hasher to its algorithm's IV so the MultiHasher can be reused
+func (multi *MultiHasher) Reset() *MultiHasher {
+	for _, hasher := range multi.hashers {
+		hasher.Reset()
+	}
+	return multi
+}
+
+// Sum returns the final sums and marks the hashers as finished to prevent additional writes
+func (multi *MultiHasher) Sum() map[HashAlgorithm][]byte {
+	var result = make(map[HashAlgorithm][]byte, len(multi.hashers))
+	for i, hasher := range multi.hashers {
+		result[multi.algorithms[i]] = hasher.SumInto(make([]byte, hasher.Size()))
+	}
+	return result
+}
+
+// Write pushes additional data into every hasher; can be called multiple times in streaming applications
+func (multi *MultiHasher) Write(message []byte) *MultiHasher {
+	if !multi.parallel || len(multi.hashers) == 1 || len(message) < parallelWriteMinimum {
+		for _, hasher := range multi.hashers {
+			hasher.Write(message)
+		}
+		return multi
+	}
+	var group sync.WaitGroup
+	group.Add(len(multi.hashers))
+	for _, hasher := range multi.hashers {
+		go func(hasher Hasher) {
+			defer group.Done()
+			hasher.Write(message)
+		}(hasher)
+	}
+	group.Wait()
+	return multi
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into every hasher
+func (multi *MultiHasher) WriteBits(message []byte, bitLength uint64) *MultiHasher {
+	for _, hasher := range multi.hashers {
+		hasher.WriteBits(message, bitLength)
+	}
+	return multi
+}
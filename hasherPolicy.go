@@ -0,0 +1,128 @@
+package hasher
+
+import (
+	"fmt"
+	"time"
+)
+
+// Purpose is the use a hasher is constructed for; SP 800-57 Part 1 sets minimum security strengths per use
+type Purpose uint32
+
+// Enumerated constant for each Purpose
+const (
+	General            Purpose = iota // Hash-only applications (collision resistance)
+	DigitalSignature   Purpose = iota // Digital signature generation and verification (collision resistance)
+	HMAC               Purpose = iota // HMAC message authentication (preimage resistance, SP 800-107)
+	KeyDerivation      Purpose = iota // Key derivation functions (preimage resistance)
+	RandomGeneration   Purpose = iota // Deterministic random bit generation (preimage resistance)
+	HashBasedSignature Purpose = iota // SP 800-208 stateful hash-based signatures (preimage resistance)
+)
+
+// Names of each Purpose for messages and audit logs
+var purposeNames = []string{"General", "DigitalSignature", "HMAC", "KeyDerivation", "RandomGeneration",
+	"HashBasedSignature"}
+
+// String returns the name of the Purpose
+func (purpose Purpose) String() string {
+	if int(purpose) < len(purposeNames) {
+		return purposeNames[purpose]
+	}
+	return fmt.Sprintf("Purpose(%d)", uint32(purpose))
+}
+
+// PolicyRule sets the minimum security strength (in bits) for a Purpose from a given instant onward
+type PolicyRule struct {
+	Purpose         Purpose   `json:"purpose"`
+	MinimumStrength int       `json:"minimumStrength"`
+	From            time.Time `json:"from"` // The zero time means the rule always applies
+}
+
+// Policy decides which algorithms may be constructed for which Purpose; New and NewBlake2 use General
+type Policy struct {
+	ApprovedOnly bool         `json:"approvedOnly"` // Also reject algorithms that are not approved for the Purpose
+	Rules        []PolicyRule `json:"rules"`
+}
+
+// The SP 800-131A transition: 112 bit security strength through 2030, 128 bit from 2031 onward
+var transition2031 = time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// SecurityPolicy is consulted by every constructor and can be overridden (e.g. with stricter rules)
+var SecurityPolicy = Policy{Rules: []PolicyRule{
+	{Purpose: DigitalSignature, MinimumStrength: 112},
+	{Purpose: DigitalSignature, MinimumStrength: 128, From: transition2031},
+	{Purpose: HMAC, MinimumStrength: 112},
+	{Purpose: HMAC, MinimumStrength: 128, From: transition2031},
+	{Purpose: KeyDerivation, MinimumStrength: 112},
+	{Purpose: KeyDerivation, MinimumStrength: 128, From: transition2031},
+	{Purpose: RandomGeneration, MinimumStrength: 112},
+	{Purpose: RandomGeneration, MinimumStrength: 128, From: transition2031},
+	{Purpose: HashBasedSignature, MinimumStrength: 192},
+}}
+
+// Approval status of each HashAlgorithm (unlisted algorithms are not approved)
+var algorithmApprovals = map[HashAlgorithm]struct {
+	approved      bool // FIPS 180-4 / FIPS 202 / SP 800-208 approved
+	hashBasedOnly bool // SP 800-208 functions are approved only for stateful hash-based signatures
+}{
+	Sha224:       {true, false},
+	Sha256:       {true, false},
+	Sha384:       {true, false},
+	Sha512:       {true, false},
+	Sha512t224:   {true, false},
+	Sha512t256:   {true, false},
+	Sha256t192:   {true, true},
+	Shake256t192: {true, true},
+}
+
+// SecurityStrength returns the security strength (bits) of hashAlgorithm for purpose per SP 800-107: half
+// the digest size where collision resistance is needed, the full digest size otherwise
+func SecurityStrength(purpose Purpose, hashAlgorithm HashAlgorithm) int {
+	return securityStrength(purpose, digestBits(hashAlgorithm))
+}
+
+// Approved reports whether hashAlgorithm is an approved service for purpose under the policy at time now
+func (policy Policy) Approved(purpose Purpose, hashAlgorithm HashAlgorithm, now time.Time) bool {
+	return approvedFor(purpose, hashAlgorithm) && policy.Check(purpose, hashAlgorithm, now) == nil
+}
+
+// Check returns nil if the policy permits hashAlgorithm for purpose at time now, or the reason it does not
+func (policy Policy) Check(purpose Purpose, hashAlgorithm HashAlgorithm, now time.Time) error {
+	return policy.check(purpose, hashAlgorithm, digestBits(hashAlgorithm), now)
+}
+
+// check applies the rules to an algorithm producing digestBits (which varies for BLAKE2)
+func (policy Policy) check(purpose Purpose, hashAlgorithm HashAlgorithm, digestBits int, now time.Time) error {
+	if policy.ApprovedOnly && !approvedFor(purpose, hashAlgorithm) {
+		return fmt.Errorf("policy: hashAlgorithm %v is not approved for purpose %v", hashAlgorithm, purpose)
+	}
+	var strength = securityStrength(purpose, digestBits)
+	for _, rule := range policy.Rules {
+		if rule.Purpose == purpose && !now.Before(rule.From) && strength < rule.MinimumStrength {
+			return fmt.Errorf("policy: hashAlgorithm %v provides %v bits for purpose %v, %v required from %v",
+				hashAlgorithm, strength, purpose, rule.MinimumStrength, rule.From.Format("2006-01-02"))
+		}
+	}
+	return nil
+}
+
+// approvedFor reports whether hashAlgorithm is an approved algorithm for purpose (ignoring strength rules)
+func approvedFor(purpose Purpose, hashAlgorithm HashAlgorithm) bool {
+	var approval = algorithmApprovals[hashAlgorithm]
+	return approval.approved && (!approval.hashBasedOnly || purpose == HashBasedSignature)
+}
+
+// digestBits returns the registered digest size of hashAlgorithm in bits (the maximum for BLAKE2)
+func digestBits(hashAlgorithm HashAlgorithm) int {
+	if info, ok := hashAlgorithm.Info(); ok {
+		return info.Size * 8
+	}
+	return 0
+}
+
+// securityStrength halves the digest size for the purposes that depend on collision resistance
+func securityStrength(purpose Purpose, digestBits int) int {
+	if purpose == General || purpose == DigitalSignature {
+		return digestBits / 2
+	}
+	return digestBits
+}
@@ -0,0 +1,64 @@
+package hasher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLimitExceeded is returned by HashReader when the reader yields more than ReaderOptions.Limit bytes
+var ErrLimitExceeded = errors.New("hasher: reader exceeded the byte limit")
+
+// ReaderOptions configures HashReader; the zero value reads to EOF without progress callbacks
+type ReaderOptions struct {
+	Progress         func(processed uint64) // Called with the bytes hashed so far (Len) as reading proceeds
+	ProgressInterval uint64                 // Bytes between Progress calls (1 MiB if zero); the last call is at EOF
+	Limit            uint64                 // Reading more than Limit bytes is an error (no limit if zero)
+	BufferSize       int                    // Size of each read (64 KiB if zero)
+}
+
+// HashReader hashes r to EOF with hashAlgorithm and returns the digest and the number of bytes hashed.
+// Cancellation of ctx is checked between reads (a Read that blocks is not interrupted) and returns ctx.Err();
+// a read error is returned as is, along with the bytes hashed before it.
+func HashReader(ctx context.Context, hashAlgorithm HashAlgorithm, r io.Reader, options ReaderOptions) ([]byte, uint64, error) {
+	var hasher = New(hashAlgorithm)
+	if hasher == nil {
+		return nil, 0, fmt.Errorf("hasher: cannot construct %v", hashAlgorithm)
+	}
+	var interval = options.ProgressInterval
+	if interval == 0 {
+		interval = 1 << 20
+	}
+	var size = options.BufferSize
+	if size < 1 {
+		size = fileBufferSize
+	}
+	var buffer = make([]byte, size)
+	var nextProgress, reported = interval, uint64(0)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, hasher.Len(), err
+		}
+		var length, err = r.Read(buffer)
+		if options.Limit > 0 && hasher.Len()+uint64(length) > options.Limit {
+			return nil, hasher.Len(), ErrLimitExceeded
+		}
+		hasher.Write(buffer[:length])
+		if options.Progress != nil && hasher.Len() >= nextProgress {
+			reported = hasher.Len()
+			options.Progress(reported)
+			nextProgress = reported - reported%interval + interval
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, hasher.Len(), err
+		}
+	}
+	if options.Progress != nil && (reported != hasher.Len() || hasher.Len() == 0) {
+		options.Progress(hasher.Len())
+	}
+	return hasher.SumInto(make([]byte, hasher.Size())), hasher.Len(), nil
+}
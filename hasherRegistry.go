@@ -0,0 +1,222 @@
+package hasher
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AlgorithmInfo is the registry metadata of a HashAlgorithm; zero values mean "no such identifier"
+type AlgorithmInfo struct {
+	Name              string                // Canonical name, e.g. "SHA-512/256"
+	Aliases           []string              // Other accepted names (matched case-insensitively)
+	OID               asn1.ObjectIdentifier // ASN.1 object identifier of the digest algorithm
+	CryptoHash        crypto.Hash           // Standard library crypto.Hash value
+	JOSE              string                // JWS HMAC "alg" built on this hash (RFC 7518)
+	COSE              int                   // COSE Algorithms registry value (RFC 9054)
+	IANA              string                // IANA Hash Function Textual Name (RFC 4572)
+	TLS               uint8                 // TLS 1.2 HashAlgorithm registry value (RFC 5246)
+	Size              int                   // Digest bytes (the maximum for BLAKE2)
+	BlockSize         int                   // Block (or sponge rate) bytes
+	CollisionStrength int                   // Security strength in bits against collisions (SP 800-107)
+	PreimageStrength  int                   // Security strength in bits against (second) preimages
+}
+
+// The registry of built-in algorithms
+var algorithms = map[HashAlgorithm]*AlgorithmInfo{
+	Sha224: {Name: "SHA-224", Aliases: []string{"SHA224", "SHA2-224", "Sha224"},
+		OID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 4}, CryptoHash: crypto.SHA224, IANA: "sha-224",
+		TLS: 3, Size: 28, BlockSize: 64, CollisionStrength: 112, PreimageStrength: 224},
+	Sha256: {Name: "SHA-256", Aliases: []string{"SHA256", "SHA2-256", "Sha256"},
+		OID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}, CryptoHash: crypto.SHA256, JOSE: "HS256",
+		COSE: -16, IANA: "sha-256", TLS: 4, Size: 32, BlockSize: 64, CollisionStrength: 128, PreimageStrength: 256},
+	Sha384: {Name: "SHA-384", Aliases: []string{"SHA384", "SHA2-384", "Sha384"},
+		OID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}, CryptoHash: crypto.SHA384, JOSE: "HS384",
+		COSE: -43, IANA: "sha-384", TLS: 5, Size: 48, BlockSize: 128, CollisionStrength: 192, PreimageStrength: 384},
+	Sha512: {Name: "SHA-512", Aliases: []string{"SHA512", "SHA2-512", "Sha512"},
+		OID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}, CryptoHash: crypto.SHA512, JOSE: "HS512",
+		COSE: -44, IANA: "sha-512", TLS: 6, Size: 64, BlockSize: 128, CollisionStrength: 256, PreimageStrength: 512},
+	Sha512t224: {Name: "SHA-512/224", Aliases: []string{"SHA512_224", "SHA2-512/224", "Sha512t224"},
+		OID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 5}, CryptoHash: crypto.SHA512_224, Size: 28,
+		BlockSize: 128, CollisionStrength: 112, PreimageStrength: 224},
+	Sha512t256: {Name: "SHA-512/256", Aliases: []string{"SHA512_256", "SHA2-512/256", "Sha512t256"},
+		OID: asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 6}, CryptoHash: crypto.SHA512_256, COSE: -17,
+		Size: 32, BlockSize: 128, CollisionStrength: 128, PreimageStrength: 256},
+	Ripemd160: {Name: "RIPEMD-160", Aliases: []string{"RIPEMD160", "RMD160", "Ripemd160"},
+		OID: asn1.ObjectIdentifier{1, 3, 36, 3, 2, 1}, CryptoHash: crypto.RIPEMD160, Size: 20, BlockSize: 64,
+		CollisionStrength: 80, PreimageStrength: 160},
+	Blake2b: {Name: "BLAKE2b-512", Aliases: []string{"BLAKE2b", "BLAKE2B512", "Blake2b"},
+		OID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 1722, 12, 2, 1, 16}, CryptoHash: crypto.BLAKE2b_512, Size: 64,
+		BlockSize: 128, CollisionStrength: 256, PreimageStrength: 512},
+	Blake2s: {Name: "BLAKE2s-256", Aliases: []string{"BLAKE2s", "BLAKE2S256", "Blake2s"},
+		OID: asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 1722, 12, 2, 2, 8}, CryptoHash: crypto.BLAKE2s_256, Size: 32,
+		BlockSize: 64, CollisionStrength: 128, PreimageStrength: 256},
+	Sha256t192: {Name: "SHA-256/192", Aliases: []string{"SHA256_192", "Sha256t192"}, Size: 24, BlockSize: 64,
+		CollisionStrength: 96, PreimageStrength: 192},
+	Shake256t192: {Name: "SHAKE256/192", Aliases: []string{"SHAKE256_192", "Shake256t192"}, Size: 24,
+		BlockSize: 136, CollisionStrength: 96, PreimageStrength: 192},
+}
+
+// Constructors of the algorithms added by RegisterAlgorithm, and the lock guarding them and the registry
+var (
+	constructors = map[HashAlgorithm]func() Hasher{}
+	registryLock sync.RWMutex
+)
+
+// RegisterAlgorithm adds a third-party Hasher implementation (e.g. hardware-backed) under a new HashAlgorithm
+// value so New, ParseHashAlgorithm and the other registry functions know it; it is typically called from init.
+// Reusing a HashAlgorithm value, name, alias or OID that is already registered is fatal; a crypto.Hash may be
+// shared (see HashAlgorithmFromCryptoHash).
+func RegisterAlgorithm(hashAlgorithm HashAlgorithm, info AlgorithmInfo, constructor func() Hasher) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	if hashAlgorithm == None || constructor == nil || info.Name == "" || info.Size < 1 {
+		LogFatal("RegisterAlgorithm() requires a HashAlgorithm other than None, a constructor, a name and a size")
+		return
+	}
+	if _, ok := algorithms[hashAlgorithm]; ok {
+		LogFatal(fmt.Sprintf("RegisterAlgorithm() HashAlgorithm %d is already registered", uint32(hashAlgorithm)))
+		return
+	}
+	for _, name := range append([]string{info.Name, info.OID.String()}, info.Aliases...) {
+		if name == "" {
+			continue
+		}
+		if existing, err := parseHashAlgorithm(name); err == nil {
+			LogFatal(fmt.Sprintf("RegisterAlgorithm() name %q is already registered to %v", name,
+				algorithms[existing].Name)) // Not %v of existing: String() would wait for the registry lock
+			return
+		}
+	}
+	var registered = info
+	registered.Aliases = append([]string(nil), info.Aliases...)
+	registered.OID = append(asn1.ObjectIdentifier(nil), info.OID...)
+	algorithms[hashAlgorithm] = &registered
+	constructors[hashAlgorithm] = constructor
+}
+
+// Algorithms returns every available HashAlgorithm, built-in and registered, in ascending order
+func Algorithms() []HashAlgorithm {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	return sortedAlgorithms()
+}
+
+// sortedAlgorithms returns the registry's HashAlgorithms in ascending order (built-ins first, since
+// registered values cannot reuse theirs); the caller holds registryLock
+func sortedAlgorithms() []HashAlgorithm {
+	var result []HashAlgorithm
+	for hashAlgorithm := range algorithms {
+		result = append(result, hashAlgorithm)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] < result[j] })
+	return result
+}
+
+// registeredConstructor returns the constructor of an algorithm added by RegisterAlgorithm
+func registeredConstructor(hashAlgorithm HashAlgorithm) (func() Hasher, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	constructor, ok := constructors[hashAlgorithm]
+	return constructor, ok
+}
+
+// String returns the canonical name of the HashAlgorithm
+func (hashAlgorithm HashAlgorithm) String() string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	if info, ok := algorithms[hashAlgorithm]; ok {
+		return info.Name
+	}
+	if hashAlgorithm == None {
+		return "None"
+	}
+	return fmt.Sprintf("HashAlgorithm(%d)", uint32(hashAlgorithm))
+}
+
+// Info returns the registry metadata of the HashAlgorithm and whether it is registered
+func (hashAlgorithm HashAlgorithm) Info() (AlgorithmInfo, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	info, ok := algorithms[hashAlgorithm]
+	if !ok {
+		return AlgorithmInfo{}, false
+	}
+	var result = *info
+	result.Aliases = append([]string(nil), info.Aliases...)
+	result.OID = append(asn1.ObjectIdentifier(nil), info.OID...)
+	return result, true
+}
+
+// MarshalText encodes the HashAlgorithm as its canonical name (so JSON carries "SHA-256" rather than 2)
+func (hashAlgorithm HashAlgorithm) MarshalText() ([]byte, error) {
+	if _, ok := hashAlgorithm.Info(); !ok && hashAlgorithm != None {
+		return nil, fmt.Errorf("hasher: cannot marshal unregistered %v", hashAlgorithm)
+	}
+	return []byte(hashAlgorithm.String()), nil
+}
+
+// UnmarshalText decodes any name accepted by ParseHashAlgorithm
+func (hashAlgorithm *HashAlgorithm) UnmarshalText(text []byte) error {
+	if string(text) == "None" {
+		*hashAlgorithm = None
+		return nil
+	}
+	parsed, err := ParseHashAlgorithm(string(text))
+	if err != nil {
+		return err
+	}
+	*hashAlgorithm = parsed
+	return nil
+}
+
+// ParseHashAlgorithm returns the HashAlgorithm with the given canonical name, alias or dotted OID
+func ParseHashAlgorithm(name string) (HashAlgorithm, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	return parseHashAlgorithm(name)
+}
+
+// parseHashAlgorithm is ParseHashAlgorithm with the registry lock already held
+func parseHashAlgorithm(name string) (HashAlgorithm, error) {
+	for hashAlgorithm, info := range algorithms {
+		if strings.EqualFold(name, info.Name) || (len(info.OID) > 0 && name == info.OID.String()) {
+			return hashAlgorithm, nil
+		}
+		for _, alias := range info.Aliases {
+			if strings.EqualFold(name, alias) {
+				return hashAlgorithm, nil
+			}
+		}
+	}
+	return None, fmt.Errorf("hasher: unknown hash algorithm %q", name)
+}
+
+// HashAlgorithmFromOID returns the HashAlgorithm identified by an ASN.1 OID (None if there is none)
+func HashAlgorithmFromOID(oid asn1.ObjectIdentifier) HashAlgorithm {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	for hashAlgorithm, info := range algorithms {
+		if len(info.OID) > 0 && info.OID.Equal(oid) {
+			return hashAlgorithm
+		}
+	}
+	return None
+}
+
+// HashAlgorithmFromCryptoHash returns the HashAlgorithm matching a crypto.Hash (None if there is none). A
+// registered implementation may share a built-in's crypto.Hash (e.g. a hardware SHA-256 as crypto.SHA256), so
+// the built-in, or else the lowest registered HashAlgorithm, is returned.
+func HashAlgorithmFromCryptoHash(hash crypto.Hash) HashAlgorithm {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	for _, hashAlgorithm := range sortedAlgorithms() {
+		if info := algorithms[hashAlgorithm]; info.CryptoHash != 0 && info.CryptoHash == hash {
+			return hashAlgorithm
+		}
+	}
+	return None
+}
@@ -0,0 +1,346 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Structure for hash160 based algorithms
+type hasher160 struct {
+	ApprovedService bool       `json:"approvedService"`
+	FillLine        int        `json:"fillLine"`
+	Finished        bool       `json:"finished"`
+	HashBlock160    *[5]uint32 `json:"hashBlock160"`
+	LenProcessed    uint64     `json:"lenProcessed"`
+	TempBlock160    *[64]byte  `json:"tempBlock160"`
+	Zeroized        bool       `json:"zeroized"`
+	ZeroizeOnSum    bool       `json:"zeroizeOnSum"`
+}
+
+// Structure personalized for ripemd160
+type ripemd160 struct {
+	hasher160 `json:"hasher160"`
+}
+
+const (
+	bYTESINBLOCK160    int = 64
+	mAXBYTESINBLOCK160 int = 56
+)
+
+// Additive constants for the left and right lines, one per round of 16 steps
+var ripemd160ConstantsLeft = [5]uint32{0x00000000, 0x5a827999, 0x6ed9eba1, 0x8f1bbcdc, 0xa953fd4e}
+var ripemd160ConstantsRight = [5]uint32{0x50a28be6, 0x5c4dd124, 0x6d703ef3, 0x7a6d76e9, 0x00000000}
+
+// Message word selection for the left and right lines
+var ripemd160WordsLeft = [80]int{
+	0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15,
+	7, 4, 13, 1, 10, 6, 15, 3, 12, 0, 9, 5, 2, 14, 11, 8,
+	3, 10, 14, 4, 9, 15, 8, 1, 2, 7, 0, 6, 13, 11, 5, 12,
+	1, 9, 11, 10, 0, 8, 12, 4, 13, 3, 7, 15, 14, 5, 6, 2,
+	4, 0, 5, 9, 7, 12, 2, 10, 14, 1, 3, 8, 11, 6, 15, 13,
+}
+var ripemd160WordsRight = [80]int{
+	5, 14, 7, 0, 9, 2, 11, 4, 13, 6, 15, 8, 1, 10, 3, 12,
+	6, 11, 3, 7, 0, 13, 5, 10, 14, 15, 8, 12, 4, 9, 1, 2,
+	15, 5, 1, 3, 7, 14, 6, 9, 11, 8, 12, 2, 10, 0, 4, 13,
+	8, 6, 4, 1, 3, 11, 15, 0, 5, 12, 2, 13, 9, 7, 10, 14,
+	12, 15, 10, 4, 1, 5, 8, 7, 6, 2, 13, 14, 0, 3, 9, 11,
+}
+
+// Left rotation amounts for the left and right lines
+var ripemd160RotationsLeft = [80]int{
+	11, 14, 15, 12, 5, 8, 7, 9, 11, 13, 14, 15, 6, 7, 9, 8,
+	7, 6, 8, 13, 11, 9, 7, 15, 7, 12, 15, 9, 11, 7, 13, 12,
+	11, 13, 6, 7, 14, 9, 13, 15, 14, 8, 13, 6, 5, 12, 7, 5,
+	11, 12, 14, 15, 14, 15, 9, 8, 9, 14, 5, 6, 8, 6, 5, 12,
+	9, 15, 5, 11, 6, 8, 13, 12, 5, 12, 13, 14, 11, 8, 5, 6,
+}
+var ripemd160RotationsRight = [80]int{
+	8, 9, 9, 11, 13, 15, 15, 5, 7, 7, 8, 11, 14, 14, 12, 6,
+	9, 13, 15, 7, 12, 8, 9, 11, 7, 7, 12, 7, 6, 15, 13, 11,
+	9, 7, 15, 11, 8, 6, 6, 14, 12, 13, 5, 14, 13, 13, 7, 5,
+	15, 5, 8, 11, 14, 14, 6, 14, 6, 9, 12, 9, 12, 5, 15, 8,
+	8, 5, 12, 9, 12, 5, 14, 6, 8, 13, 6, 5, 15, 13, 11, 11,
+}
+
+// Hash160 returns RIPEMD-160(SHA-256(message)) as used for Bitcoin addresses and scripts
+func Hash160(message []byte) [20]byte {
+	var sha256Sum = New(Sha256).Write(message).Sum().([32]byte)
+	return New(Ripemd160).Write(sha256Sum[:]).Sum().([20]byte)
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose)
+func (hasher *ripemd160) Approved() bool {
+	return hasher.ApprovedService
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *ripemd160) BlockSize() int {
+	return bYTESINBLOCK160
+}
+
+// Copy returns a deep copy
+func (hasher *ripemd160) Copy() Hasher {
+	return hasherCopy(newHasher(Ripemd160), hasher)
+}
+
+// Destroy zeroizes the chaining state and pending block; the hasher cannot be used again until Reset
+func (hasher *ripemd160) Destroy() {
+	zeroize160(&hasher.hasher160)
+}
+
+// HashAlgorithm returns the hash algorithm of the "object"
+func (hasher *ripemd160) HashAlgorithm() HashAlgorithm {
+	return Ripemd160
+}
+
+// InterimSum returns "the sum so far" without finalizing the original hasher
+func (hasher ripemd160) InterimSum() interface{} {
+	return hasher.Copy().Sum()
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *ripemd160) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *ripemd160) Reset() Hasher {
+	reset160(&hasher.hasher160, &[5]uint32{ // The specific/unique initial conditions for RIPEMD-160 h[0:4]
+		0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0,
+	})
+	return hasher
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *ripemd160) Size() int {
+	return 20
+}
+
+// Sum returns the final sum and marks the hasher as finished to prevent additional writes
+func (hasher *ripemd160) Sum() interface{} {
+	var digest [20]byte
+	sum160(&hasher.hasher160, digest[:])
+	return digest
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *ripemd160) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 20); dst != nil {
+		sum160(&hasher.hasher160, dst)
+	}
+	return dst
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *ripemd160) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *ripemd160) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *ripemd160) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// Write pushes additional data into the hasher; can be called multiple times in streaming applications
+func (hasher *ripemd160) Write(message []byte) Hasher {
+	write160(&hasher.hasher160, message)
+	return hasher
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; only whole bytes are supported
+func (hasher *ripemd160) WriteBits(message []byte, bitLength uint64) Hasher {
+	return writeAlignedBits(hasher, message, bitLength)
+}
+
+// init creates an initialized structure specific to the algorithm in play
+func (hasher *ripemd160) init(hashAlgorithm HashAlgorithm) Hasher {
+	hasher.TempBlock160 = &[64]byte{0}
+	hasher.HashBlock160 = &[5]uint32{}
+	return hasher.Reset()
+}
+
+// approve sets the approved service indicator
+func (hasher *hasher160) approve(approved bool) {
+	hasher.ApprovedService = approved
+}
+
+// zeroizeOnSum sets whether Sum wipes the state once the digest has been computed
+func (hasher *hasher160) zeroizeOnSum(zeroize bool) {
+	hasher.ZeroizeOnSum = zeroize
+}
+
+// reset160 returns the engine to the specified IV without reallocating, ready for a new message
+func reset160(hasher *hasher160, iv *[5]uint32) {
+	*hasher.HashBlock160 = *iv
+	*hasher.TempBlock160 = [64]byte{}
+	hasher.FillLine = 0
+	hasher.Finished = false
+	hasher.LenProcessed = 0
+	hasher.Zeroized = false
+}
+
+// zeroize160 wipes all message dependent state and marks the hasher finished and zeroized
+func zeroize160(hasher *hasher160) {
+	*hasher.HashBlock160 = [5]uint32{}
+	*hasher.TempBlock160 = [64]byte{}
+	hasher.FillLine = 0
+	hasher.LenProcessed = 0
+	hasher.Finished = true
+	hasher.Zeroized = true
+}
+
+// write160 does the real work of message ingestion
+func write160(hasher *hasher160, message []byte) {
+	if hasher.Finished {
+		LogFatal("Cannot call Write() after Sum() because the hasher has been finalized")
+	}
+	if hasher.LenProcessed+uint64(len(message)) < hasher.LenProcessed {
+		LogFatal("Total message length of 2**64 has been exceeded")
+	}
+
+	hasher.LenProcessed += uint64(len(message))
+
+	// If tempBlock is partly filled: top it up, and hash it once full
+	if hasher.FillLine > 0 {
+		var length = copy(hasher.TempBlock160[hasher.FillLine:], message)
+		hasher.FillLine += length
+		message = message[length:]
+		if hasher.FillLine < bYTESINBLOCK160 {
+			return
+		}
+		oneBlock160(hasher, hasher.TempBlock160[:])
+		hasher.FillLine = 0
+	}
+
+	// Hash whole blocks straight from the message (no copying, recursion or allocation)
+	for len(message) >= bYTESINBLOCK160 {
+		oneBlock160(hasher, message[:bYTESINBLOCK160])
+		message = message[bYTESINBLOCK160:]
+	}
+
+	// Park any remainder in the (now empty) tempBlock
+	hasher.FillLine = copy(hasher.TempBlock160[:], message)
+}
+
+// sum160 finalizes (once) and writes the leading len(digest) bytes of the hash value into digest
+func sum160(hasher *hasher160, digest []byte) {
+	if hasher.Zeroized {
+		LogFatal("Sum() after the hasher state was zeroized")
+	}
+	if !hasher.Finished {
+		finalize160(hasher)
+	}
+	hasher.Finished = true
+	for index := 0; index < len(digest); index += 4 {
+		binary.LittleEndian.PutUint32(digest[index:index+4], hasher.HashBlock160[index/4])
+	}
+	if hasher.ZeroizeOnSum {
+		zeroize160(hasher)
+	}
+}
+
+// finalize160 finishes the calculation by padding, marking length, and hashing final block(s)
+func finalize160(hasher *hasher160) {
+	// Finalize by hashing last block if padding will fit
+	if hasher.FillLine < mAXBYTESINBLOCK160 {
+		lastBlock160(hasher)
+	}
+
+	// Finalize by hashing two last blocks if padding will NOT fit
+	if hasher.FillLine >= mAXBYTESINBLOCK160 && hasher.FillLine < bYTESINBLOCK160 {
+		fillBlock160(hasher)
+		oneBlock160(hasher, hasher.TempBlock160[:])
+		hasher.FillLine = 0
+		fillBlock160(hasher)
+		hasher.TempBlock160[hasher.FillLine] = 0
+		tagLength160(hasher)
+		oneBlock160(hasher, hasher.TempBlock160[:])
+	}
+
+	// Clear working data
+	hasher.FillLine = 0
+	fillBlock160(hasher)
+}
+
+// fillBlock160 sets the message-end marker and zeros the remainder
+func fillBlock160(hasher *hasher160) {
+	hasher.TempBlock160[hasher.FillLine] = 128 // Set MSB
+	for index := hasher.FillLine + 1; index < bYTESINBLOCK160; index++ {
+		hasher.TempBlock160[index] = 0x00 // Clear MSB
+	}
+}
+
+// tagLength160 put the (little-endian) length field into the message end
+func tagLength160(hasher *hasher160) {
+	binary.LittleEndian.PutUint64(hasher.TempBlock160[mAXBYTESINBLOCK160:bYTESINBLOCK160], hasher.LenProcessed*8)
+}
+
+// lastBlock160 nearly done!
+func lastBlock160(hasher *hasher160) {
+	fillBlock160(hasher)
+	tagLength160(hasher)
+	oneBlock160(hasher, hasher.TempBlock160[:])
+}
+
+// ripemd160Function is the round-dependent boolean function f(j, x, y, z)
+func ripemd160Function(round int, x uint32, y uint32, z uint32) uint32 {
+	switch round {
+	case 0:
+		return x ^ y ^ z
+	case 1:
+		return (x & y) | (^x & z)
+	case 2:
+		return (x | ^y) ^ z
+	case 3:
+		return (x & z) | (y & ^z)
+	default:
+		return x ^ (y | ^z)
+	}
+}
+
+// oneBlock160 does one full hash block iteration with the two parallel lines
+func oneBlock160(hasher *hasher160, message []byte) {
+	// The message words are per call and wiped before returning
+	var x [16]uint32
+	defer wipeWords160(&x)
+	for i := 0; i < 16; i++ {
+		x[i] = binary.LittleEndian.Uint32(message[i*4 : i*4+4])
+	}
+
+	// Initialize working variables for both lines
+	var al, bl, cl, dl, el = hasher.HashBlock160[0], hasher.HashBlock160[1], hasher.HashBlock160[2],
+		hasher.HashBlock160[3], hasher.HashBlock160[4]
+	var ar, br, cr, dr, er = al, bl, cl, dl, el
+
+	for j := 0; j < 80; j++ {
+		round := j / 16
+		t := bits.RotateLeft32(al+ripemd160Function(round, bl, cl, dl)+x[ripemd160WordsLeft[j]]+
+			ripemd160ConstantsLeft[round], ripemd160RotationsLeft[j]) + el
+		al, el, dl, cl, bl = el, dl, bits.RotateLeft32(cl, 10), bl, t
+
+		t = bits.RotateLeft32(ar+ripemd160Function(4-round, br, cr, dr)+x[ripemd160WordsRight[j]]+
+			ripemd160ConstantsRight[round], ripemd160RotationsRight[j]) + er
+		ar, er, dr, cr, br = er, dr, bits.RotateLeft32(cr, 10), br, t
+	}
+
+	// Combine both lines into the chaining value
+	var t = hasher.HashBlock160[1] + cl + dr
+	hasher.HashBlock160[1] = hasher.HashBlock160[2] + dl + er
+	hasher.HashBlock160[2] = hasher.HashBlock160[3] + el + ar
+	hasher.HashBlock160[3] = hasher.HashBlock160[4] + al + br
+	hasher.HashBlock160[4] = hasher.HashBlock160[0] + bl + cr
+	hasher.HashBlock160[0] = t
+}
+
+// wipeWords160 overwrites the message words (not inlined, so the stores cannot be optimized away)
+//
+//go:noinline
+func wipeWords160(words *[16]uint32) {
+	*words = [16]uint32{}
+}
@@ -0,0 +1,146 @@
+package hasher
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SelfTestMode selects when the known-answer self-tests run
+type SelfTestMode uint32
+
+// Enumerated constant for each self-test mode
+const (
+	PowerOnSelfTest     SelfTestMode = iota // Every algorithm is tested on the first call to New
+	ConditionalSelfTest SelfTestMode = iota // Each algorithm is tested on its first instantiation
+	ManualSelfTest      SelfTestMode = iota // Only explicit calls to SelfTest() run the tests
+)
+
+// SelfTestSetting can be overridden (before first use) to select when the self-tests run
+var SelfTestSetting = PowerOnSelfTest
+
+// SelfTestResult records the outcome of one self-test for audit logging
+type SelfTestResult struct {
+	Test          string        `json:"test"`          // "KAT" or "Integrity"
+	HashAlgorithm HashAlgorithm `json:"hashAlgorithm"` // None for the integrity self-check
+	Passed        bool          `json:"passed"`
+	Expected      string        `json:"expected"`
+	Actual        string        `json:"actual"`
+	Time          time.Time     `json:"time"`
+}
+
+// Known-answer digests of the message "abc" for each HashAlgorithm
+var selfTestVectors = map[HashAlgorithm]string{
+	Sha224:       "23097d223405d8228642a477bda255b32aadbce4bda0b3f7e36c9da7",
+	Sha256:       "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	Sha384:       "cb00753f45a35e8bb5a03d699ac65007272c32ab0eded1631a8b605a43ff5bed8086072ba1e7cc2358baeca134c825a7",
+	Sha512:       "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+	Sha512t224:   "4634270f707b6a54daae7530460842e20e37ed265ceee9a43e8924aa",
+	Sha512t256:   "53048e2681941ef99b2e29b76b4c7dabe4c2d0c634fc6d46e0e2f13107e7af23",
+	Ripemd160:    "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc",
+	Blake2b:      "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d17d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923",
+	Blake2s:      "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982",
+	Sha256t192:   "ba7816bf8f01cfea414140de5dae2223b00361a396177a9c",
+	Shake256t192: "483366601360a8771c6863080cc4114d8db44530f8f1e1ee",
+}
+
+// Digest (SHA-256) of the round constant, IV and permutation tables checked by the integrity self-check
+var selfTestIntegrity = "51c3a1eeabc1c7feb9021c97638a1674bf3b00860f8c695da63702787193ef12"
+
+// Self-test state shared by all constructors; the flags are atomic so that constructors only take the lock
+// while self-tests are due, and tests run and record under the lock
+var selfTestState struct {
+	sync.Mutex
+	errorState atomic.Bool
+	powerOnRun atomic.Bool
+	results    []SelfTestResult
+	tested     atomic.Uint64 // Bit n set once the known-answer test of HashAlgorithm n has run
+}
+
+// SelfTest runs the integrity self-check and the known-answer test of every HashAlgorithm now and returns
+// the results; any failure puts the package into the error state in which New refuses to construct hashers
+func SelfTest() []SelfTestResult {
+	selfTestState.Lock()
+	defer selfTestState.Unlock()
+	var results = []SelfTestResult{selfTestTables()}
+	for hashAlgorithm := Sha224; hashAlgorithm <= Shake256t192; hashAlgorithm++ {
+		results = append(results, selfTestOne(hashAlgorithm))
+	}
+	selfTestState.powerOnRun.Store(true)
+	return results
+}
+
+// SelfTestResults returns every self-test result recorded so far, oldest first
+func SelfTestResults() []SelfTestResult {
+	selfTestState.Lock()
+	defer selfTestState.Unlock()
+	return append([]SelfTestResult(nil), selfTestState.results...)
+}
+
+// SelfTestErrorState reports whether a self-test has failed (and New is refusing to construct hashers)
+func SelfTestErrorState() bool {
+	return selfTestState.errorState.Load()
+}
+
+// selfTestGate runs any self-tests due under SelfTestSetting and reports whether hashers may be constructed
+func selfTestGate(hashAlgorithm HashAlgorithm) bool {
+	if !selfTestDue(hashAlgorithm) {
+		return !selfTestState.errorState.Load()
+	}
+	selfTestState.Lock()
+	defer selfTestState.Unlock()
+	if selfTestDue(hashAlgorithm) { // Another constructor may have run it while this one waited
+		switch SelfTestSetting {
+		case PowerOnSelfTest:
+			selfTestTables()
+			for algorithm := Sha224; algorithm <= Shake256t192; algorithm++ {
+				selfTestOne(algorithm)
+			}
+			selfTestState.powerOnRun.Store(true)
+		case ConditionalSelfTest:
+			selfTestOne(hashAlgorithm)
+		}
+	}
+	return !selfTestState.errorState.Load()
+}
+
+// selfTestDue reports whether SelfTestSetting requires a self-test before hashAlgorithm is constructed
+func selfTestDue(hashAlgorithm HashAlgorithm) bool {
+	switch SelfTestSetting {
+	case PowerOnSelfTest:
+		return !selfTestState.powerOnRun.Load()
+	case ConditionalSelfTest:
+		var _, ok = selfTestVectors[hashAlgorithm]
+		return ok && selfTestState.tested.Load()&(1<<hashAlgorithm) == 0
+	}
+	return false
+}
+
+// selfTestOne runs the known-answer test for one HashAlgorithm and records the result (lock held)
+func selfTestOne(hashAlgorithm HashAlgorithm) SelfTestResult {
+	selfTestState.tested.Or(1 << hashAlgorithm)
+	return selfTestRecord(SelfTestResult{Test: "KAT", HashAlgorithm: hashAlgorithm,
+		Expected: selfTestVectors[hashAlgorithm],
+		Actual:   fmt.Sprintf("%x", newHasher(hashAlgorithm).Write([]byte("abc")).Sum())})
+}
+
+// selfTestTables runs the integrity self-check over the constant tables and records the result (lock held)
+func selfTestTables() SelfTestResult {
+	var tables = fmt.Sprint(sha256Constants, *sha512Constants, ripemd160ConstantsLeft, ripemd160ConstantsRight,
+		ripemd160WordsLeft, ripemd160WordsRight, ripemd160RotationsLeft, ripemd160RotationsRight, blake2bIV,
+		blake2sIV, blake2Sigma, keccakRoundConstants, keccakRotations, keccakLanes)
+	return selfTestRecord(SelfTestResult{Test: "Integrity", Expected: selfTestIntegrity,
+		Actual: fmt.Sprintf("%x", newHasher(Sha256).Write([]byte(tables)).Sum())})
+}
+
+// selfTestRecord timestamps and records a result, entering the error state on failure (lock held)
+func selfTestRecord(result SelfTestResult) SelfTestResult {
+	result.Time = time.Now().UTC()
+	result.Passed = result.Expected != "" && result.Actual == result.Expected
+	if !result.Passed {
+		selfTestState.errorState.Store(true)
+	}
+	selfTestState.results = append(selfTestState.results, result)
+	return result
+}
@@ -7,11 +7,16 @@ import (
 
 // Structure for hash256 based algorithms
 type hasher256 struct {
-	FillLine     int        `json:"fillLine"`
-	Finished     bool       `json:"finished"`
-	HashBlock256 *[8]uint32 `json:"hashBlock256"`
-	LenProcessed uint64     `json:"lenProcessed"`
-	TempBlock256 *[64]byte  `json:"tempBlock256"`
+	ApprovedService bool       `json:"approvedService"`
+	FillLine        int        `json:"fillLine"`
+	Finished        bool       `json:"finished"`
+	HashBlock256    *[8]uint32 `json:"hashBlock256"`
+	Imported        bool       `json:"imported"` // Resumed from a midstate not known to be approved
+	LenProcessed    uint64     `json:"lenProcessed"`
+	PartialBits     int        `json:"partialBits"`
+	TempBlock256    *[64]byte  `json:"tempBlock256"`
+	Zeroized        bool       `json:"zeroized"`
+	ZeroizeOnSum    bool       `json:"zeroizeOnSum"`
 }
 
 // Structure personalized for sha224
@@ -24,6 +29,11 @@ type sha256 struct {
 	hasher256 `json:"hasher256"`
 }
 
+// Structure personalized for sha256t192 (SP 800-208 SHA-256/192: SHA-256 truncated to 192 bits)
+type sha256t192 struct {
+	hasher256 `json:"hasher256t192"`
+}
+
 const (
 	bYTESINBLOCK256    int = 64
 	mAXBYTESINBLOCK256 int = 56
@@ -40,14 +50,67 @@ var sha256Constants = [64]uint32{
 	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
 }
 
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha224) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha256) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha256t192) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha224) BlockSize() int {
+	return bYTESINBLOCK256
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha256) BlockSize() int {
+	return bYTESINBLOCK256
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha256t192) BlockSize() int {
+	return bYTESINBLOCK256
+}
+
 // Copy returns a deep copy
 func (hasher *sha224) Copy() Hasher {
-	return hasherCopy(New(Sha224), hasher)
+	return hasherCopy(newHasher(Sha224), hasher)
 }
 
 // Copy returns a deep copy
 func (hasher *sha256) Copy() Hasher {
-	return hasherCopy(New(Sha256), hasher)
+	return hasherCopy(newHasher(Sha256), hasher)
+}
+
+// Copy returns a deep copy
+func (hasher *sha256t192) Copy() Hasher {
+	return hasherCopy(newHasher(Sha256t192), hasher)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha224) Destroy() {
+	zeroize256(&hasher.hasher256)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha256) Destroy() {
+	zeroize256(&hasher.hasher256)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha256t192) Destroy() {
+	zeroize256(&hasher.hasher256)
 }
 
 // HashAlgorithm returns the hash algorithm of the "object"
@@ -60,6 +123,11 @@ func (hasher *sha256) HashAlgorithm() HashAlgorithm {
 	return Sha256
 }
 
+// HashAlgorithm returns the hash algorithm of the "object"
+func (hasher *sha256t192) HashAlgorithm() HashAlgorithm {
+	return Sha256t192
+}
+
 // InterimSum returns "the sum so far" without finalizing the original hasher
 func (hasher sha224) InterimSum() interface{} {
 	return hasher.Copy().Sum()
@@ -70,66 +138,269 @@ func (hasher sha256) InterimSum() interface{} {
 	return hasher.Copy().Sum()
 }
 
+// InterimSum returns "the sum so far" without finalizing the original hasher
+func (hasher sha256t192) InterimSum() interface{} {
+	return hasher.Copy().Sum()
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha224) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha256) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha256t192) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha224) Reset() Hasher {
+	reset256(&hasher.hasher256, &[8]uint32{ // The specific/unique initial conditions for SHA-224 H[0:7]
+		0xc1059ed8, 0x367cd507, 0x3070dd17, 0xf70e5939, 0xffc00b31, 0x68581511, 0x64f98fa7, 0xbefa4fa4,
+	})
+	return hasher
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha256) Reset() Hasher {
+	reset256(&hasher.hasher256, &[8]uint32{ // The specific/unique initial conditions for SHA-256 H[0:7]
+		0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+	})
+	return hasher
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha256t192) Reset() Hasher {
+	reset256(&hasher.hasher256, &[8]uint32{ // Unlike SHA-224, SHA-256/192 keeps the SHA-256 H[0:7]
+		0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+	})
+	return hasher
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha224) Size() int {
+	return 28
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha256) Size() int {
+	return 32
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha256t192) Size() int {
+	return 24
+}
+
 // Sum returns the final sum and marks the hasher as finished to prevent additional writes
 func (hasher *sha224) Sum() interface{} {
-	if !hasher.Finished {
-		finalize256(&hasher.hasher256)
-	}
-	hasher.Finished = true
 	var digest [28]byte
-	for index := 0; index < 28; index += 4 {
-		binary.BigEndian.PutUint32(digest[index:index+4], hasher.HashBlock256[index/4])
-	}
+	sum256(&hasher.hasher256, digest[:])
 	return digest
 }
 
 // Sum returns the final sum and marks the hasher as finished to prevent additional writes
 func (hasher *sha256) Sum() interface{} {
-	if !hasher.Finished {
-		finalize256(&hasher.hasher256)
-	}
-	hasher.Finished = true
 	var digest [32]byte
-	for index := 0; index < 32; index += 4 {
-		binary.BigEndian.PutUint32(digest[index:index+4], hasher.HashBlock256[index/4])
-	}
+	sum256(&hasher.hasher256, digest[:])
+	return digest
+}
+
+// Sum returns the final sum and marks the hasher as finished to prevent additional writes
+func (hasher *sha256t192) Sum() interface{} {
+	var digest [24]byte
+	sum256(&hasher.hasher256, digest[:])
 	return digest
 }
 
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha224) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 28); dst != nil {
+		sum256(&hasher.hasher256, dst)
+	}
+	return dst
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha256) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 32); dst != nil {
+		sum256(&hasher.hasher256, dst)
+	}
+	return dst
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha256t192) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 24); dst != nil {
+		sum256(&hasher.hasher256, dst)
+	}
+	return dst
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha224) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha256) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha256t192) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha224) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha256) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha256t192) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha224) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha256) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha256t192) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
 // Write pushes additional data into the hasher; can be called multiple times in streaming applications
 func (hasher *sha224) Write(message []byte) Hasher {
 	write256(&hasher.hasher256, message)
 	return hasher
 }
 
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha224) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits256(&hasher.hasher256, message, bitLength)
+	return hasher
+}
+
 // Write pushes additional data into the hasher; can be called multiple times in streaming applications
 func (hasher *sha256) Write(message []byte) Hasher {
 	write256(&hasher.hasher256, message)
 	return hasher
 }
 
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha256) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits256(&hasher.hasher256, message, bitLength)
+	return hasher
+}
+
+// Write pushes additional data into the hasher; can be called multiple times in streaming applications
+func (hasher *sha256t192) Write(message []byte) Hasher {
+	write256(&hasher.hasher256, message)
+	return hasher
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha256t192) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits256(&hasher.hasher256, message, bitLength)
+	return hasher
+}
+
 // init creates an initialized structure specific to the algorithm in play
 func (hasher *sha224) init(hashAlgorithm HashAlgorithm) Hasher {
-	hasher.LenProcessed = 0
 	hasher.TempBlock256 = &[64]byte{0}
-	hasher.HashBlock256 = &[8]uint32{ // The specific/unique initial conditions for SHA-224 H[0:7]
-		0xc1059ed8, 0x367cd507, 0x3070dd17, 0xf70e5939, 0xffc00b31, 0x68581511, 0x64f98fa7, 0xbefa4fa4,
-	}
-	return hasher
+	hasher.HashBlock256 = &[8]uint32{}
+	return hasher.Reset()
 }
 
 // init creates an initialized structure specific to the algorithm in play
 func (hasher *sha256) init(hashAlgorithm HashAlgorithm) Hasher {
-	hasher.LenProcessed = 0
 	hasher.TempBlock256 = &[64]byte{0}
-	hasher.HashBlock256 = &[8]uint32{ // The specific/unique initial conditions for SHA-256 H[0:7]
-		0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+	hasher.HashBlock256 = &[8]uint32{}
+	return hasher.Reset()
+}
+
+// init creates an initialized structure specific to the algorithm in play
+func (hasher *sha256t192) init(hashAlgorithm HashAlgorithm) Hasher {
+	hasher.TempBlock256 = &[64]byte{0}
+	hasher.HashBlock256 = &[8]uint32{}
+	return hasher.Reset()
+}
+
+// approve sets the approved service indicator
+func (hasher *hasher256) approve(approved bool) {
+	hasher.ApprovedService = approved
+}
+
+// zeroizeOnSum sets whether Sum wipes the state once the digest has been computed
+func (hasher *hasher256) zeroizeOnSum(zeroize bool) {
+	hasher.ZeroizeOnSum = zeroize
+}
+
+// midstate returns the chaining value (big-endian) and the bytes hashed, and whether they are block-aligned
+func (hasher *hasher256) midstate() ([]byte, uint64, bool) {
+	var chainingValue = make([]byte, 32)
+	for i, word := range hasher.HashBlock256 {
+		binary.BigEndian.PutUint32(chainingValue[i*4:], word)
 	}
-	return hasher
+	return chainingValue, hasher.LenProcessed,
+		hasher.FillLine == 0 && hasher.PartialBits == 0 && !hasher.Finished && !hasher.Zeroized
+}
+
+// setMidstate resumes from a chaining value (big-endian) after length block-aligned bytes
+func (hasher *hasher256) setMidstate(chainingValue []byte, length uint64) {
+	for i := range hasher.HashBlock256 {
+		hasher.HashBlock256[i] = binary.BigEndian.Uint32(chainingValue[i*4:])
+	}
+	hasher.LenProcessed = length
+	hasher.Imported = true
+}
+
+// reset256 returns the engine to the specified IV without reallocating, ready for a new message
+func reset256(hasher *hasher256, iv *[8]uint32) {
+	*hasher.HashBlock256 = *iv
+	*hasher.TempBlock256 = [64]byte{}
+	hasher.FillLine = 0
+	hasher.Finished = false
+	hasher.LenProcessed = 0
+	hasher.Imported = false
+	hasher.PartialBits = 0
+	hasher.Zeroized = false
+}
+
+// zeroize256 wipes all message dependent state and marks the hasher finished and zeroized
+func zeroize256(hasher *hasher256) {
+	*hasher.HashBlock256 = [8]uint32{}
+	*hasher.TempBlock256 = [64]byte{}
+	hasher.FillLine = 0
+	hasher.LenProcessed = 0
+	hasher.PartialBits = 0
+	hasher.Finished = true
+	hasher.Zeroized = true
 }
 
 // write256 does the real work of message ingestion
 func write256(hasher *hasher256, message []byte) {
+	if hasher.PartialBits > 0 {
+		LogFatal("Cannot call Write() after WriteBits() ended the message with a partial byte")
+	}
 	if hasher.Finished {
 		LogFatal("Cannot call Write() after Sum() because the hasher has been finalized")
 	}
@@ -137,36 +408,57 @@ func write256(hasher *hasher256, message []byte) {
 		LogFatal("Total message length of 2**64 has been exceeded")
 	}
 
-	// If message fits into non-empty tempBlock without filling it: append, adjust status and finish
-	if len(message)+hasher.FillLine < bYTESINBLOCK256 {
-		copy(hasher.TempBlock256[hasher.FillLine:hasher.FillLine+len(message)], message)
-		hasher.LenProcessed += uint64(len(message))
-		hasher.FillLine += len(message)
-		return
+	hasher.LenProcessed += uint64(len(message))
+
+	// If tempBlock is partly filled: top it up, and hash it once full
+	if hasher.FillLine > 0 {
+		var length = copy(hasher.TempBlock256[hasher.FillLine:], message)
+		hasher.FillLine += length
+		message = message[length:]
+		if hasher.FillLine < bYTESINBLOCK256 {
+			return
+		}
+		blocks256(hasher, hasher.TempBlock256[:])
+		hasher.FillLine = 0
 	}
 
-	// If message can fill non-empty tempBlock: append, hash it and call back with message remainder
-	if hasher.FillLine > 0 && len(message)+hasher.FillLine > (bYTESINBLOCK256-1) {
-		copy(hasher.TempBlock256[hasher.FillLine:hasher.FillLine+(bYTESINBLOCK256-hasher.FillLine)], message)
-		hasher.LenProcessed += uint64(bYTESINBLOCK256 - hasher.FillLine)
-		oneBlock256(hasher, hasher.TempBlock256[:])
-		var tempFill = bYTESINBLOCK256 - hasher.FillLine
-		hasher.FillLine = 0
-		write256(hasher, message[tempFill:]) // One-off recursion
-		return
+	// Hash whole blocks straight from the message (no copying, recursion or allocation)
+	if whole := len(message) - len(message)%bYTESINBLOCK256; whole > 0 {
+		blocks256(hasher, message[:whole])
+		message = message[whole:]
 	}
 
-	// If empty tempBlock and message > block size: hash block-by-block
-	var index int
-	for (hasher.FillLine == 0) && (len(message)-index > (bYTESINBLOCK256 - 1)) {
-		oneBlock256(hasher, message[index:index+bYTESINBLOCK256])
-		index += bYTESINBLOCK256
-		hasher.LenProcessed += uint64(bYTESINBLOCK256)
+	// Park any remainder in the (now empty) tempBlock
+	hasher.FillLine = copy(hasher.TempBlock256[:], message)
+}
+
+// writeBits256 ingests whole bytes, then parks any partial final byte (leftmost bits) in tempBlock
+func writeBits256(hasher *hasher256, message []byte, bitLength uint64) {
+	if bitLength > uint64(len(message))*8 {
+		LogFatal("WriteBits() bitLength exceeds the message length")
+		return
 	}
+	write256(hasher, message[:bitLength/8])
+	if bitLength%8 > 0 {
+		hasher.PartialBits = int(bitLength % 8)
+		hasher.TempBlock256[hasher.FillLine] = message[bitLength/8] & ^(0xff >> hasher.PartialBits)
+	}
+}
 
-	// If message segment remainder exists: call back
-	if len(message)-index > 0 {
-		write256(hasher, message[index:]) // One-off recursion
+// sum256 finalizes (once) and writes the leading len(digest) bytes of the hash value into digest
+func sum256(hasher *hasher256, digest []byte) {
+	if hasher.Zeroized {
+		LogFatal("Sum() after the hasher state was zeroized")
+	}
+	if !hasher.Finished {
+		finalize256(hasher)
+	}
+	hasher.Finished = true
+	for index := 0; index < len(digest); index += 4 {
+		binary.BigEndian.PutUint32(digest[index:index+4], hasher.HashBlock256[index/4])
+	}
+	if hasher.ZeroizeOnSum {
+		zeroize256(hasher)
 	}
 }
 
@@ -180,12 +472,12 @@ func finalize256(hasher *hasher256) {
 	// Finalize by hashing two last blocks if padding will NOT fit
 	if hasher.FillLine >= mAXBYTESINBLOCK256 && hasher.FillLine < bYTESINBLOCK256 {
 		fillBlock256(hasher)
-		oneBlock256(hasher, hasher.TempBlock256[:])
+		blocks256(hasher, hasher.TempBlock256[:])
 		hasher.FillLine = 0
 		fillBlock256(hasher)
 		hasher.TempBlock256[hasher.FillLine] = 0
 		tagLength256(hasher)
-		oneBlock256(hasher, hasher.TempBlock256[:])
+		blocks256(hasher, hasher.TempBlock256[:])
 	}
 
 	// Clear working data
@@ -195,7 +487,8 @@ func finalize256(hasher *hasher256) {
 
 // fillBlock256 sets the message-end marker and zeros the remainder
 func fillBlock256(hasher *hasher256) {
-	hasher.TempBlock256[hasher.FillLine] = 128 // Set MSB
+	hasher.TempBlock256[hasher.FillLine] &= ^(0xff >> hasher.PartialBits) // Keep any partial byte bits
+	hasher.TempBlock256[hasher.FillLine] |= 0x80 >> hasher.PartialBits    // Set the bit after the message
 	for index := hasher.FillLine + 1; index < bYTESINBLOCK256; index++ {
 		hasher.TempBlock256[index] = 0x00 // Clear MSB
 	}
@@ -203,22 +496,35 @@ func fillBlock256(hasher *hasher256) {
 
 // tagLength256 put the length field into the message end
 func tagLength256(hasher *hasher256) {
-	hasher.LenProcessed *= 8
-	binary.BigEndian.PutUint64(hasher.TempBlock256[mAXBYTESINBLOCK256:bYTESINBLOCK256], hasher.LenProcessed)
+	var bitLength = hasher.LenProcessed*8 + uint64(hasher.PartialBits) // LenProcessed itself stays in bytes
+	hasher.PartialBits = 0
+	binary.BigEndian.PutUint64(hasher.TempBlock256[mAXBYTESINBLOCK256:bYTESINBLOCK256], bitLength)
 }
 
 // lastBlock256 nearly done!
 func lastBlock256(hasher *hasher256) {
 	fillBlock256(hasher)
 	tagLength256(hasher)
-	oneBlock256(hasher, hasher.TempBlock256[:])
+	blocks256(hasher, hasher.TempBlock256[:])
 }
 
-// Message schedule (faster out here)
-var w256 [64]uint32
+// blocks256 hashes whole blocks with the assembly kernel when one was selected, else the pure-Go reference
+func blocks256(hasher *hasher256, message []byte) {
+	if block256Kernel != nil {
+		block256Kernel(hasher.HashBlock256, message)
+		return
+	}
+	for ; len(message) > 0; message = message[bYTESINBLOCK256:] {
+		oneBlock256(hasher, message[:bYTESINBLOCK256])
+	}
+}
 
-// oneBlock256 does one full hash block iteration
+// oneBlock256 does one full hash block iteration (the pure-Go reference kernel)
 func oneBlock256(hasher *hasher256, message []byte) {
+	// The message schedule is per call (concurrent hashers never share it) and wiped before returning
+	var w256 [64]uint32
+	defer wipeSchedule256(&w256)
+
 	// First 16 w256 are straightforward
 	for i := 0; i < 16; i++ {
 		j := i * 4
@@ -318,3 +624,10 @@ func oneBlock256(hasher *hasher256, message []byte) {
 	hasher.HashBlock256[6] += g
 	hasher.HashBlock256[7] += h
 }
+
+// wipeSchedule256 overwrites a message schedule (not inlined, so the stores cannot be optimized away)
+//
+//go:noinline
+func wipeSchedule256(schedule *[64]uint32) {
+	*schedule = [64]uint32{}
+}
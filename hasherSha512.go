@@ -7,11 +7,16 @@ import (
 
 // Structure for hash256 based algorithms
 type hasher512 struct {
-	FillLine     int        `json:"fillLine"`
-	Finished     bool       `json:"finished"`
-	HashBlock512 *[8]uint64 `json:"hashBlock512"`
-	LenProcessed uint64     `json:"lenProcessed"`
-	TempBlock512 *[128]byte `json:"tempBlock512"`
+	ApprovedService bool       `json:"approvedService"`
+	FillLine        int        `json:"fillLine"`
+	Finished        bool       `json:"finished"`
+	HashBlock512    *[8]uint64 `json:"hashBlock512"`
+	Imported        bool       `json:"imported"` // Resumed from a midstate not known to be approved
+	LenProcessed    uint64     `json:"lenProcessed"`
+	PartialBits     int        `json:"partialBits"`
+	TempBlock512    *[128]byte `json:"tempBlock512"`
+	Zeroized        bool       `json:"zeroized"`
+	ZeroizeOnSum    bool       `json:"zeroizeOnSum"`
 }
 
 // Structure personalized for sha384
@@ -58,24 +63,88 @@ var sha512Constants = &[80]uint64{
 	0x431d67c49c100d4c, 0x4cc5d4becb3e42b6, 0x597f299cfc657e2a, 0x5fcb6fab3ad6faec, 0x6c44198c4a475817,
 }
 
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha384) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha512) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha512t224) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose, not
+// resumed from an imported midstate)
+func (hasher *sha512t256) Approved() bool {
+	return hasher.ApprovedService && !hasher.Imported
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha384) BlockSize() int {
+	return bYTESINBLOCK512
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha512) BlockSize() int {
+	return bYTESINBLOCK512
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha512t224) BlockSize() int {
+	return bYTESINBLOCK512
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *sha512t256) BlockSize() int {
+	return bYTESINBLOCK512
+}
+
 // Copy returns a deep copy
 func (hasher *sha384) Copy() Hasher {
-	return hasherCopy(New(Sha384), hasher)
+	return hasherCopy(newHasher(Sha384), hasher)
 }
 
 // Copy returns a deep copy
 func (hasher *sha512) Copy() Hasher {
-	return hasherCopy(New(Sha512), hasher)
+	return hasherCopy(newHasher(Sha512), hasher)
 }
 
 // Copy returns a deep copy
 func (hasher *sha512t224) Copy() Hasher {
-	return hasherCopy(New(Sha512t224), hasher)
+	return hasherCopy(newHasher(Sha512t224), hasher)
 }
 
 // Copy returns a deep copy
 func (hasher *sha512t256) Copy() Hasher {
-	return hasherCopy(New(Sha512t256), hasher)
+	return hasherCopy(newHasher(Sha512t256), hasher)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha384) Destroy() {
+	zeroize512(&hasher.hasher512)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha512) Destroy() {
+	zeroize512(&hasher.hasher512)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha512t224) Destroy() {
+	zeroize512(&hasher.hasher512)
+}
+
+// Destroy zeroizes the chaining state, pending block and message schedule; the hasher cannot be used again until Reset
+func (hasher *sha512t256) Destroy() {
+	zeroize512(&hasher.hasher512)
 }
 
 // HashAlgorithm returns the hash algorithm of the "object"
@@ -120,129 +189,335 @@ func (hasher sha512t256) InterimSum() interface{} {
 	return hasher.Copy().Sum()
 }
 
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha384) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha512) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha512t224) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *sha512t256) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha384) Reset() Hasher {
+	reset512(&hasher.hasher512, &[8]uint64{ // The specific/unique initial conditions for SHA-384 H[0:7]
+		0xcbbb9d5dc1059ed8, 0x629a292a367cd507, 0x9159015a3070dd17, 0x152fecd8f70e5939,
+		0x67332667ffc00b31, 0x8eb44a8768581511, 0xdb0c2e0d64f98fa7, 0x47b5481dbefa4fa4,
+	})
+	return hasher
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha512) Reset() Hasher {
+	reset512(&hasher.hasher512, &[8]uint64{ // The specific/unique initial conditions for SHA-512 H[0:7]
+		0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+		0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+	})
+	return hasher
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha512t224) Reset() Hasher {
+	reset512(&hasher.hasher512, &[8]uint64{ // The specific/unique initial conditions for SHA-512t224 H[0:7]
+		0x8C3D37C819544DA2, 0x73E1996689DCD4D6, 0x1DFAB7AE32FF9C82, 0x679DD514582F9FCF,
+		0x0F6D2B697BD44DA8, 0x77E36F7304C48942, 0x3F9D85A86A1D36C8, 0x1112E6AD91D692A1,
+	})
+	return hasher
+}
+
+// Reset returns the hasher to the algorithm's IV (without reallocating) so it can be reused, even after Sum
+func (hasher *sha512t256) Reset() Hasher {
+	reset512(&hasher.hasher512, &[8]uint64{ // The specific/unique initial conditions for SHA-512t256 H[0:7]
+		0x22312194FC2BF72C, 0x9F555FA3C84C64C2, 0x2393B86B6F53B151, 0x963877195940EABD,
+		0x96283EE2A88EFFE3, 0xBE5E1E2553863992, 0x2B0199FC2C85B8AA, 0x0EB72DDC81C52CA2,
+	})
+	return hasher
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha384) Size() int {
+	return 48
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha512) Size() int {
+	return 64
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha512t224) Size() int {
+	return 28
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *sha512t256) Size() int {
+	return 32
+}
+
 // Sum returns the final sum and marks the hasher as finished to prevent additional writes
 func (hasher *sha384) Sum() interface{} {
-	if !hasher.Finished {
-		finalize512(&hasher.hasher512)
-	}
-	hasher.Finished = true
 	var digest [48]byte
-	for index := 0; index < 48; index += 8 {
-		binary.BigEndian.PutUint64(digest[index:index+8], hasher.HashBlock512[index/8])
-	}
+	sum512(&hasher.hasher512, digest[:])
 	return digest
 }
 
 // Sum returns the final sum and marks the hasher as finished to prevent additional writes
 func (hasher *sha512) Sum() interface{} {
-	if !hasher.Finished {
-		finalize512(&hasher.hasher512)
-	}
-	hasher.Finished = true
 	var digest [64]byte
-	for index := 0; index < 64; index += 8 {
-		binary.BigEndian.PutUint64(digest[index:index+8], hasher.HashBlock512[index/8])
-	}
+	sum512(&hasher.hasher512, digest[:])
 	return digest
 }
 
 // Sum returns the final sum and marks the hasher as finished to prevent additional writes
 func (hasher *sha512t224) Sum() interface{} {
-	if !hasher.Finished {
-		finalize512(&hasher.hasher512)
-	}
-	hasher.Finished = true
 	var digest [28]byte
-	for index := 0; index < 24; index += 8 {
-		binary.BigEndian.PutUint64(digest[index:index+8], hasher.HashBlock512[index/8])
-	}
-	binary.BigEndian.PutUint32(digest[24:28], uint32(hasher.HashBlock512[3]>>32)) // Pesky left-over
+	sum512(&hasher.hasher512, digest[:])
 	return digest
 }
 
 // Sum returns the final sum and marks the hasher as finished to prevent additional writes
 func (hasher *sha512t256) Sum() interface{} {
-	if !hasher.Finished {
-		finalize512(&hasher.hasher512)
-	}
-	hasher.Finished = true
 	var digest [32]byte
-	for index := 0; index < 32; index += 8 {
-		binary.BigEndian.PutUint64(digest[index:index+8], hasher.HashBlock512[index/8])
-	}
+	sum512(&hasher.hasher512, digest[:])
 	return digest
 }
 
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha384) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 48); dst != nil {
+		sum512(&hasher.hasher512, dst)
+	}
+	return dst
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha512) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 64); dst != nil {
+		sum512(&hasher.hasher512, dst)
+	}
+	return dst
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha512t224) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 28); dst != nil {
+		sum512(&hasher.hasher512, dst)
+	}
+	return dst
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *sha512t256) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 32); dst != nil {
+		sum512(&hasher.hasher512, dst)
+	}
+	return dst
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha384) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha512) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha512t224) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *sha512t256) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha384) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha512) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha512t224) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *sha512t256) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha384) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha512) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha512t224) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *sha512t256) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
 // Write pushes additional data into the hasher; can be called multiple times in streaming applications
 func (hasher *sha384) Write(message []byte) Hasher {
 	write512(&hasher.hasher512, message)
 	return hasher
 }
 
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha384) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits512(&hasher.hasher512, message, bitLength)
+	return hasher
+}
+
 // Write pushes additional data into the hasher; can be called multiple times in streaming applications
 func (hasher *sha512) Write(message []byte) Hasher {
 	write512(&hasher.hasher512, message)
 	return hasher
 }
 
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha512) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits512(&hasher.hasher512, message, bitLength)
+	return hasher
+}
+
 // Write pushes additional data into the hasher; can be called multiple times in streaming applications
 func (hasher *sha512t224) Write(message []byte) Hasher {
 	write512(&hasher.hasher512, message)
 	return hasher
 }
 
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha512t224) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits512(&hasher.hasher512, message, bitLength)
+	return hasher
+}
+
 // Write pushes additional data into the hasher; can be called multiple times in streaming applications
 func (hasher *sha512t256) Write(message []byte) Hasher {
 	write512(&hasher.hasher512, message)
 	return hasher
 }
 
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; a partial final byte ends the message
+func (hasher *sha512t256) WriteBits(message []byte, bitLength uint64) Hasher {
+	writeBits512(&hasher.hasher512, message, bitLength)
+	return hasher
+}
+
 // init creates an initialized structure specific to the algorithm in play
 func (hasher *sha384) init(hashAlgorithm HashAlgorithm) Hasher {
-	hasher.LenProcessed = 0
 	hasher.TempBlock512 = &[128]byte{0}
-	hasher.HashBlock512 = &[8]uint64{ // The specific/unique initial conditions for SHA-384 H[0:7]
-		0xcbbb9d5dc1059ed8, 0x629a292a367cd507, 0x9159015a3070dd17, 0x152fecd8f70e5939,
-		0x67332667ffc00b31, 0x8eb44a8768581511, 0xdb0c2e0d64f98fa7, 0x47b5481dbefa4fa4,
-	}
-	return hasher
+	hasher.HashBlock512 = &[8]uint64{}
+	return hasher.Reset()
 }
 
 // init creates an initialized structure specific to the algorithm in play
 func (hasher *sha512) init(hashAlgorithm HashAlgorithm) Hasher {
-	hasher.LenProcessed = 0
 	hasher.TempBlock512 = &[128]byte{0}
-	hasher.HashBlock512 = &[8]uint64{ // The specific/unique initial conditions for SHA-512 H[0:7]
-		0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
-		0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
-	}
-	return hasher
+	hasher.HashBlock512 = &[8]uint64{}
+	return hasher.Reset()
 }
 
 // init creates an initialized structure specific to the algorithm in play
 func (hasher *sha512t224) init(hashAlgorithm HashAlgorithm) Hasher {
-	hasher.LenProcessed = 0
 	hasher.TempBlock512 = &[128]byte{0}
-	hasher.HashBlock512 = &[8]uint64{ // The specific/unique initial conditions for SHA-512t224 H[0:7]
-		0x8C3D37C819544DA2, 0x73E1996689DCD4D6, 0x1DFAB7AE32FF9C82, 0x679DD514582F9FCF,
-		0x0F6D2B697BD44DA8, 0x77E36F7304C48942, 0x3F9D85A86A1D36C8, 0x1112E6AD91D692A1,
-	}
-	return hasher
+	hasher.HashBlock512 = &[8]uint64{}
+	return hasher.Reset()
 }
 
 // init creates an initialized structure specific to the algorithm in play
 func (hasher *sha512t256) init(hashAlgorithm HashAlgorithm) Hasher {
-	hasher.LenProcessed = 0
 	hasher.TempBlock512 = &[128]byte{0}
-	hasher.HashBlock512 = &[8]uint64{ // The specific/unique initial conditions for SHA-512t256 H[0:7]
-		0x22312194FC2BF72C, 0x9F555FA3C84C64C2, 0x2393B86B6F53B151, 0x963877195940EABD,
-		0x96283EE2A88EFFE3, 0xBE5E1E2553863992, 0x2B0199FC2C85B8AA, 0x0EB72DDC81C52CA2,
+	hasher.HashBlock512 = &[8]uint64{}
+	return hasher.Reset()
+}
+
+// approve sets the approved service indicator
+func (hasher *hasher512) approve(approved bool) {
+	hasher.ApprovedService = approved
+}
+
+// zeroizeOnSum sets whether Sum wipes the state once the digest has been computed
+func (hasher *hasher512) zeroizeOnSum(zeroize bool) {
+	hasher.ZeroizeOnSum = zeroize
+}
+
+// midstate returns the chaining value (big-endian) and the bytes hashed, and whether they are block-aligned
+func (hasher *hasher512) midstate() ([]byte, uint64, bool) {
+	var chainingValue = make([]byte, 64)
+	for i, word := range hasher.HashBlock512 {
+		binary.BigEndian.PutUint64(chainingValue[i*8:], word)
 	}
-	return hasher
+	return chainingValue, hasher.LenProcessed,
+		hasher.FillLine == 0 && hasher.PartialBits == 0 && !hasher.Finished && !hasher.Zeroized
+}
+
+// setMidstate resumes from a chaining value (big-endian) after length block-aligned bytes
+func (hasher *hasher512) setMidstate(chainingValue []byte, length uint64) {
+	for i := range hasher.HashBlock512 {
+		hasher.HashBlock512[i] = binary.BigEndian.Uint64(chainingValue[i*8:])
+	}
+	hasher.LenProcessed = length
+	hasher.Imported = true
+}
+
+// reset512 returns the engine to the specified IV without reallocating, ready for a new message
+func reset512(hasher *hasher512, iv *[8]uint64) {
+	*hasher.HashBlock512 = *iv
+	*hasher.TempBlock512 = [128]byte{}
+	hasher.FillLine = 0
+	hasher.Finished = false
+	hasher.LenProcessed = 0
+	hasher.Imported = false
+	hasher.PartialBits = 0
+	hasher.Zeroized = false
+}
+
+// zeroize512 wipes all message dependent state and marks the hasher finished and zeroized
+func zeroize512(hasher *hasher512) {
+	*hasher.HashBlock512 = [8]uint64{}
+	*hasher.TempBlock512 = [128]byte{}
+	hasher.FillLine = 0
+	hasher.LenProcessed = 0
+	hasher.PartialBits = 0
+	hasher.Finished = true
+	hasher.Zeroized = true
 }
 
 // write512 does the real work of message ingestion
 func write512(hasher *hasher512, message []byte) {
+	if hasher.PartialBits > 0 {
+		LogFatal("Cannot call Write() after WriteBits() ended the message with a partial byte")
+	}
 	if hasher.Finished {
 		LogFatal("Cannot call Write() after Sum() because hasher has been finalized")
 	}
@@ -250,38 +525,61 @@ func write512(hasher *hasher512, message []byte) {
 		LogFatal("Total message length of 2**64 has been exceeded")
 	}
 
-	// If message fits into non-empty tempBlock without filling it: append, adjust status and finish
-	if len(message)+hasher.FillLine < bYTESINBLOCK512 {
-		copy(hasher.TempBlock512[hasher.FillLine:hasher.FillLine+len(message)], message)
-		hasher.LenProcessed += uint64(len(message))
-		hasher.FillLine += len(message)
-		return
-	}
-
-	// If message can fill non-empty tempBlock: append, hash it and call back with message remainder
-	if hasher.FillLine > 0 && len(message)+hasher.FillLine > (bYTESINBLOCK512-1) {
-		copy(hasher.TempBlock512[hasher.FillLine:hasher.FillLine+(bYTESINBLOCK512-hasher.FillLine)], message)
-		hasher.LenProcessed += uint64(bYTESINBLOCK512 - hasher.FillLine)
-		oneBlock512(hasher, hasher.TempBlock512[:])
-		var tempFill = bYTESINBLOCK512 - hasher.FillLine
+	hasher.LenProcessed += uint64(len(message))
+
+	// If tempBlock is partly filled: top it up, and hash it once full
+	if hasher.FillLine > 0 {
+		var length = copy(hasher.TempBlock512[hasher.FillLine:], message)
+		hasher.FillLine += length
+		message = message[length:]
+		if hasher.FillLine < bYTESINBLOCK512 {
+			return
+		}
+		blocks512(hasher, hasher.TempBlock512[:])
 		hasher.FillLine = 0
-		write512(hasher, message[tempFill:]) // One-off recursion
-		return
 	}
 
-	// If empty tempBlock and message > block size: hash block-by-block
-	var index int
-	for (hasher.FillLine == 0) && (len(message)-index > (bYTESINBLOCK512 - 1)) {
-		oneBlock512(hasher, message[index:index+bYTESINBLOCK512])
-		index += bYTESINBLOCK512
-		hasher.LenProcessed += uint64(bYTESINBLOCK512)
+	// Hash whole blocks straight from the message (no copying, recursion or allocation)
+	if whole := len(message) - len(message)%bYTESINBLOCK512; whole > 0 {
+		blocks512(hasher, message[:whole])
+		message = message[whole:]
 	}
 
-	// If message segment remainder exists: call back
-	if len(message)-index > 0 {
-		write512(hasher, message[index:]) // One-off recursion
+	// Park any remainder in the (now empty) tempBlock
+	hasher.FillLine = copy(hasher.TempBlock512[:], message)
+}
+
+// writeBits512 ingests whole bytes, then parks any partial final byte (leftmost bits) in tempBlock
+func writeBits512(hasher *hasher512, message []byte, bitLength uint64) {
+	if bitLength > uint64(len(message))*8 {
+		LogFatal("WriteBits() bitLength exceeds the message length")
 		return
 	}
+	write512(hasher, message[:bitLength/8])
+	if bitLength%8 > 0 {
+		hasher.PartialBits = int(bitLength % 8)
+		hasher.TempBlock512[hasher.FillLine] = message[bitLength/8] & ^(0xff >> hasher.PartialBits)
+	}
+}
+
+// sum512 finalizes (once) and writes the leading len(digest) bytes of the hash value into digest
+func sum512(hasher *hasher512, digest []byte) {
+	if hasher.Zeroized {
+		LogFatal("Sum() after the hasher state was zeroized")
+	}
+	if !hasher.Finished {
+		finalize512(hasher)
+	}
+	hasher.Finished = true
+	for index := 0; index+8 <= len(digest); index += 8 {
+		binary.BigEndian.PutUint64(digest[index:index+8], hasher.HashBlock512[index/8])
+	}
+	if len(digest)%8 > 0 { // Pesky left-over (SHA-512/224)
+		binary.BigEndian.PutUint32(digest[len(digest)-4:], uint32(hasher.HashBlock512[len(digest)/8]>>32))
+	}
+	if hasher.ZeroizeOnSum {
+		zeroize512(hasher)
+	}
 }
 
 // finalize512 finishes the calculation by padding, marking length, and hashing final block(s)
@@ -294,12 +592,12 @@ func finalize512(hasher *hasher512) {
 	// Finalize by hashing two last blocks if padding will NOT fit
 	if hasher.FillLine >= mAXBYTESINBLOCK512 && hasher.FillLine < bYTESINBLOCK512 {
 		fillBlock512(hasher)
-		oneBlock512(hasher, hasher.TempBlock512[:])
+		blocks512(hasher, hasher.TempBlock512[:])
 		hasher.FillLine = 0
 		fillBlock512(hasher)
 		hasher.TempBlock512[hasher.FillLine] = 0
 		tagLength512(hasher)
-		oneBlock512(hasher, hasher.TempBlock512[:])
+		blocks512(hasher, hasher.TempBlock512[:])
 	}
 
 	// Clear working data
@@ -309,7 +607,8 @@ func finalize512(hasher *hasher512) {
 
 // fillBlock512 sets the message-end marker and zeros the remainder
 func fillBlock512(hasher *hasher512) {
-	hasher.TempBlock512[hasher.FillLine] = 128 // Set MSB
+	hasher.TempBlock512[hasher.FillLine] &= ^(0xff >> hasher.PartialBits) // Keep any partial byte bits
+	hasher.TempBlock512[hasher.FillLine] |= 0x80 >> hasher.PartialBits    // Set the bit after the message
 	for index := hasher.FillLine + 1; index < bYTESINBLOCK512; index++ {
 		hasher.TempBlock512[index] = 0x00 // Clear MSB
 	}
@@ -317,22 +616,35 @@ func fillBlock512(hasher *hasher512) {
 
 // tagLength512 put the length field into the message end
 func tagLength512(hasher *hasher512) {
-	hasher.LenProcessed *= 8
-	binary.BigEndian.PutUint64(hasher.TempBlock512[mAXBYTESINBLOCK512+8:bYTESINBLOCK512], hasher.LenProcessed)
+	var bitLength = hasher.LenProcessed*8 + uint64(hasher.PartialBits) // LenProcessed itself stays in bytes
+	hasher.PartialBits = 0
+	binary.BigEndian.PutUint64(hasher.TempBlock512[mAXBYTESINBLOCK512+8:bYTESINBLOCK512], bitLength)
 }
 
 // lastBlock512 nearly done!
 func lastBlock512(hasher *hasher512) {
 	fillBlock512(hasher)
 	tagLength512(hasher)
-	oneBlock512(hasher, hasher.TempBlock512[:])
+	blocks512(hasher, hasher.TempBlock512[:])
 }
 
-// Message schedule (faster out here)
-var w512 [80]uint64
+// blocks512 hashes whole blocks with the assembly kernel when one was selected, else the pure-Go reference
+func blocks512(hasher *hasher512, message []byte) {
+	if block512Kernel != nil {
+		block512Kernel(hasher.HashBlock512, message)
+		return
+	}
+	for ; len(message) > 0; message = message[bYTESINBLOCK512:] {
+		oneBlock512(hasher, message[:bYTESINBLOCK512])
+	}
+}
 
-// oneBlock256 does one full hash block iteration
+// oneBlock512 does one full hash block iteration (the pure-Go reference kernel)
 func oneBlock512(hasher *hasher512, message []byte) {
+	// The message schedule is per call (concurrent hashers never share it) and wiped before returning
+	var w512 [80]uint64
+	defer wipeSchedule512(&w512)
+
 	// First 16 w512 are straightforward
 	for i := 0; i < 16; i++ {
 		j := i * 8
@@ -431,3 +743,10 @@ func oneBlock512(hasher *hasher512, message []byte) {
 	hasher.HashBlock512[6] += g
 	hasher.HashBlock512[7] += h
 }
+
+// wipeSchedule512 overwrites a message schedule (not inlined, so the stores cannot be optimized away)
+//
+//go:noinline
+func wipeSchedule512(schedule *[80]uint64) {
+	*schedule = [80]uint64{}
+}
@@ -0,0 +1,262 @@
+package hasher
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// Structure for Keccak-f[1600] sponge based algorithms
+type hasher1600 struct {
+	ApprovedService bool        `json:"approvedService"`
+	FillLine        int         `json:"fillLine"`
+	Finished        bool        `json:"finished"`
+	LenProcessed    uint64      `json:"lenProcessed"`
+	State1600       *[25]uint64 `json:"state1600"`
+	TempBlock136    *[136]byte  `json:"tempBlock136"`
+	Zeroized        bool        `json:"zeroized"`
+	ZeroizeOnSum    bool        `json:"zeroizeOnSum"`
+}
+
+// Structure personalized for shake256t192
+type shake256t192 struct {
+	hasher1600 `json:"hasher1600"`
+}
+
+const (
+	bYTESINRATE256 int  = 136  // SHAKE256 rate: 1600 - 2*256 bits
+	sHAKEDOMAIN    byte = 0x1f // SHAKE domain separation bits plus the first padding bit
+)
+
+var keccakRoundConstants = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// Combined rho rotations and pi lane order, walking the pi permutation from lane 1
+var keccakRotations = [24]int{1, 3, 6, 10, 15, 21, 28, 36, 45, 55, 2, 14, 27, 41, 56, 8, 25, 43, 62, 18, 39, 61, 20, 44}
+var keccakLanes = [24]int{10, 7, 11, 17, 18, 3, 5, 16, 8, 21, 24, 4, 15, 23, 19, 13, 12, 2, 20, 14, 22, 9, 6, 1}
+
+// Approved returns the approved service indicator (an approved algorithm permitted for its purpose)
+func (hasher *shake256t192) Approved() bool {
+	return hasher.ApprovedService
+}
+
+// BlockSize returns the number of bytes the algorithm processes per block (its rate, for sponges)
+func (hasher *shake256t192) BlockSize() int {
+	return bYTESINRATE256
+}
+
+// Copy returns a deep copy
+func (hasher *shake256t192) Copy() Hasher {
+	return hasherCopy(newHasher(Shake256t192), hasher)
+}
+
+// Destroy zeroizes the chaining state and pending block; the hasher cannot be used again until Reset
+func (hasher *shake256t192) Destroy() {
+	zeroize1600(&hasher.hasher1600)
+}
+
+// HashAlgorithm returns the hash algorithm of the "object"
+func (hasher *shake256t192) HashAlgorithm() HashAlgorithm {
+	return Shake256t192
+}
+
+// InterimSum returns "the sum so far" without finalizing the original hasher
+func (hasher shake256t192) InterimSum() interface{} {
+	return hasher.Copy().Sum()
+}
+
+// Len returns the number of (whole) message bytes written so far
+func (hasher *shake256t192) Len() uint64 {
+	return hasher.LenProcessed
+}
+
+// Reset returns the hasher to the all-zero sponge state (without reallocating) so it can be reused, even after Sum
+func (hasher *shake256t192) Reset() Hasher {
+	reset1600(&hasher.hasher1600)
+	return hasher
+}
+
+// Size returns the number of bytes Sum returns
+func (hasher *shake256t192) Size() int {
+	return 24
+}
+
+// Sum returns the final sum and marks the hasher as finished to prevent additional writes
+func (hasher *shake256t192) Sum() interface{} {
+	var digest [24]byte
+	sum1600(&hasher.hasher1600, digest[:])
+	return digest
+}
+
+// SumInto is Sum writing into dst (which must hold Size() bytes) rather than allocating; it returns dst[:Size()]
+func (hasher *shake256t192) SumInto(dst []byte) []byte {
+	if dst = sumDestination(dst, 24); dst != nil {
+		sum1600(&hasher.hasher1600, dst)
+	}
+	return dst
+}
+
+// Verify finalizes the hasher and compares the sum with expected in constant time
+func (hasher *shake256t192) Verify(expected []byte) bool {
+	return verify(hasher, expected)
+}
+
+// VerifyBase64 is Verify for a standard (padded) base64 encoded expected digest
+func (hasher *shake256t192) VerifyBase64(expected string) bool {
+	return verifyBase64(hasher, expected)
+}
+
+// VerifyHex is Verify for a hex encoded expected digest
+func (hasher *shake256t192) VerifyHex(expected string) bool {
+	return verifyHex(hasher, expected)
+}
+
+// Write pushes additional data into the hasher; can be called multiple times in streaming applications
+func (hasher *shake256t192) Write(message []byte) Hasher {
+	write1600(&hasher.hasher1600, message)
+	return hasher
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into the hasher; only whole bytes are supported
+func (hasher *shake256t192) WriteBits(message []byte, bitLength uint64) Hasher {
+	return writeAlignedBits(hasher, message, bitLength)
+}
+
+// init creates an initialized structure specific to the algorithm in play
+func (hasher *shake256t192) init(hashAlgorithm HashAlgorithm) Hasher {
+	hasher.TempBlock136 = &[136]byte{0}
+	hasher.State1600 = &[25]uint64{0}
+	return hasher.Reset()
+}
+
+// approve sets the approved service indicator
+func (hasher *hasher1600) approve(approved bool) {
+	hasher.ApprovedService = approved
+}
+
+// zeroizeOnSum sets whether Sum wipes the state once the digest has been computed
+func (hasher *hasher1600) zeroizeOnSum(zeroize bool) {
+	hasher.ZeroizeOnSum = zeroize
+}
+
+// reset1600 returns the engine to the all-zero sponge state without reallocating
+func reset1600(hasher *hasher1600) {
+	*hasher.State1600 = [25]uint64{} // The sponge always starts from the all-zero state
+	*hasher.TempBlock136 = [136]byte{}
+	hasher.FillLine = 0
+	hasher.Finished = false
+	hasher.LenProcessed = 0
+	hasher.Zeroized = false
+}
+
+// zeroize1600 wipes all message dependent state and marks the hasher finished and zeroized
+func zeroize1600(hasher *hasher1600) {
+	*hasher.State1600 = [25]uint64{}
+	*hasher.TempBlock136 = [136]byte{}
+	hasher.FillLine = 0
+	hasher.LenProcessed = 0
+	hasher.Finished = true
+	hasher.Zeroized = true
+}
+
+// write1600 does the real work of message ingestion (absorbing)
+func write1600(hasher *hasher1600, message []byte) {
+	if hasher.Finished {
+		LogFatal("Cannot call Write() after Sum() because the hasher has been finalized")
+	}
+	if hasher.LenProcessed+uint64(len(message)) < hasher.LenProcessed {
+		LogFatal("Total message length of 2**64 has been exceeded")
+	}
+	hasher.LenProcessed += uint64(len(message))
+
+	for len(message) > 0 {
+		var length = copy(hasher.TempBlock136[hasher.FillLine:], message)
+		hasher.FillLine += length
+		message = message[length:]
+		if hasher.FillLine == bYTESINRATE256 {
+			oneBlock1600(hasher, hasher.TempBlock136[:])
+			hasher.FillLine = 0
+		}
+	}
+}
+
+// sum1600 finalizes (once) and writes the leading len(digest) bytes of the hash value into digest
+func sum1600(hasher *hasher1600, digest []byte) {
+	if hasher.Zeroized {
+		LogFatal("Sum() after the hasher state was zeroized")
+	}
+	if !hasher.Finished {
+		finalize1600(hasher)
+	}
+	hasher.Finished = true
+	for index := 0; index < len(digest); index += 8 {
+		binary.LittleEndian.PutUint64(digest[index:index+8], hasher.State1600[index/8])
+	}
+	if hasher.ZeroizeOnSum {
+		zeroize1600(hasher)
+	}
+}
+
+// finalize1600 finishes absorbing by adding the domain bits and pad10*1 to the last block
+func finalize1600(hasher *hasher1600) {
+	hasher.TempBlock136[hasher.FillLine] = sHAKEDOMAIN
+	for index := hasher.FillLine + 1; index < bYTESINRATE256; index++ {
+		hasher.TempBlock136[index] = 0x00
+	}
+	hasher.TempBlock136[bYTESINRATE256-1] |= 0x80 // Final padding bit
+	oneBlock1600(hasher, hasher.TempBlock136[:])
+
+	// Clear working data
+	hasher.FillLine = 0
+	for index := range hasher.TempBlock136 {
+		hasher.TempBlock136[index] = 0x00
+	}
+}
+
+// oneBlock1600 absorbs one rate-sized block and applies Keccak-f[1600]
+func oneBlock1600(hasher *hasher1600, message []byte) {
+	for i := 0; i < bYTESINRATE256/8; i++ {
+		hasher.State1600[i] ^= binary.LittleEndian.Uint64(message[i*8 : i*8+8])
+	}
+	keccakF1600(hasher.State1600)
+}
+
+// keccakF1600 is the 24-round Keccak permutation
+func keccakF1600(state *[25]uint64) {
+	var c [5]uint64
+	for round := 0; round < 24; round++ {
+		// Theta
+		for i := 0; i < 5; i++ {
+			c[i] = state[i] ^ state[i+5] ^ state[i+10] ^ state[i+15] ^ state[i+20]
+		}
+		for i := 0; i < 5; i++ {
+			t := c[(i+4)%5] ^ bits.RotateLeft64(c[(i+1)%5], 1)
+			for j := 0; j < 25; j += 5 {
+				state[j+i] ^= t
+			}
+		}
+
+		// Rho and pi
+		t := state[1]
+		for i := 0; i < 24; i++ {
+			j := keccakLanes[i]
+			t, state[j] = state[j], bits.RotateLeft64(t, keccakRotations[i])
+		}
+
+		// Chi
+		for j := 0; j < 25; j += 5 {
+			copy(c[:], state[j:j+5])
+			for i := 0; i < 5; i++ {
+				state[j+i] ^= ^c[(i+1)%5] & c[(i+2)%5]
+			}
+		}
+
+		// Iota
+		state[0] ^= keccakRoundConstants[round]
+	}
+}
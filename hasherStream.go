@@ -0,0 +1,180 @@
+package hasher
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrIntegrity matches (with errors.Is) every IntegrityError
+var ErrIntegrity = errors.New("hasher: content does not match the expected digest")
+
+// IntegrityError is returned by a VerifyingReader at EOF when the digest of the content is not the expected one
+type IntegrityError struct {
+	HashAlgorithm HashAlgorithm
+	Expected      []byte
+	Actual        []byte
+}
+
+// Error describes the mismatch
+func (integrityError *IntegrityError) Error() string {
+	return fmt.Sprintf("hasher: %v digest %x does not match the expected %x", integrityError.HashAlgorithm,
+		integrityError.Actual, integrityError.Expected)
+}
+
+// Is makes errors.Is(err, ErrIntegrity) true for an IntegrityError
+func (integrityError *IntegrityError) Is(target error) bool {
+	return target == ErrIntegrity
+}
+
+// HashingReader hashes everything read through it (like io.TeeReader with a Hasher)
+type HashingReader struct {
+	reader io.Reader
+	hasher Hasher
+}
+
+// NewHashingReader returns a HashingReader reading from reader into hasher
+func NewHashingReader(reader io.Reader, hasher Hasher) *HashingReader {
+	return &HashingReader{reader: reader, hasher: hasher}
+}
+
+// Hasher returns the Hasher (e.g. for Sum once the reader is exhausted)
+func (hashingReader *HashingReader) Hasher() Hasher {
+	return hashingReader.hasher
+}
+
+// InterimSum returns the digest of the bytes read so far
+func (hashingReader *HashingReader) InterimSum() interface{} {
+	return hashingReader.hasher.InterimSum()
+}
+
+// Read reads from the underlying reader and hashes the bytes read
+func (hashingReader *HashingReader) Read(buffer []byte) (int, error) {
+	var length, err = hashingReader.reader.Read(buffer)
+	hashingReader.hasher.Write(buffer[:length])
+	return length, err
+}
+
+// HashingWriter hashes everything written through it (like io.MultiWriter with a Hasher)
+type HashingWriter struct {
+	writer io.Writer
+	hasher Hasher
+}
+
+// NewHashingWriter returns a HashingWriter writing to writer (nil to only hash) and into hasher
+func NewHashingWriter(writer io.Writer, hasher Hasher) *HashingWriter {
+	return &HashingWriter{writer: writer, hasher: hasher}
+}
+
+// Hasher returns the Hasher (e.g. for Sum once writing is complete)
+func (hashingWriter *HashingWriter) Hasher() Hasher {
+	return hashingWriter.hasher
+}
+
+// InterimSum returns the digest of the bytes written so far
+func (hashingWriter *HashingWriter) InterimSum() interface{} {
+	return hashingWriter.hasher.InterimSum()
+}
+
+// Write writes to the underlying writer and hashes the bytes it accepted
+func (hashingWriter *HashingWriter) Write(buffer []byte) (int, error) {
+	var length, err = len(buffer), error(nil)
+	if hashingWriter.writer != nil {
+		length, err = hashingWriter.writer.Write(buffer)
+	}
+	hashingWriter.hasher.Write(buffer[:length])
+	return length, err
+}
+
+// Size of the chunks a withholding VerifyingReader reads
+const verifyChunkSize = 32 * 1024
+
+// VerifyingReader hashes everything read through it and returns an IntegrityError instead of io.EOF if the
+// digest is not the expected one. With withholding, the last chunk read is only released once it is verified,
+// so a consumer never sees the end of tampered content.
+type VerifyingReader struct {
+	reader   io.Reader
+	hasher   Hasher
+	expected []byte
+	withhold bool
+	buffers  [2][]byte // Withholding: the withheld chunk and the next read alternate between these
+	held     int       // Index of the buffer holding the withheld chunk
+	heldLen  int
+	ready    []byte // Bytes released to the caller
+	verified bool   // The underlying reader is exhausted and the digest matched
+	err      error  // Returned once ready is drained
+}
+
+// NewVerifyingReader returns a VerifyingReader reading from reader into hasher and checking for expected; with
+// an expected digest of the wrong length every Read fails with an error wrapping ErrDigestLength
+func NewVerifyingReader(reader io.Reader, hasher Hasher, expected []byte, withholdLast bool) *VerifyingReader {
+	var verifier = &VerifyingReader{reader: reader, hasher: hasher, expected: append([]byte(nil), expected...),
+		withhold: withholdLast}
+	if len(expected) != hasher.Size() {
+		verifier.err = digestLengthError(hasher, len(expected))
+	}
+	return verifier
+}
+
+// Read reads from the underlying reader, hashes the bytes read and verifies the digest at EOF
+func (verifier *VerifyingReader) Read(buffer []byte) (int, error) {
+	if !verifier.withhold {
+		if verifier.err != nil {
+			return 0, verifier.err
+		}
+		var length, err = verifier.reader.Read(buffer)
+		verifier.hasher.Write(buffer[:length])
+		if err == io.EOF {
+			if err = verifier.verify(); err == nil {
+				err = io.EOF
+			}
+		}
+		verifier.err = err
+		return length, err
+	}
+	for len(verifier.ready) == 0 && verifier.err == nil {
+		verifier.fill()
+	}
+	if length := copy(buffer, verifier.ready); length > 0 {
+		verifier.ready = verifier.ready[length:]
+		return length, nil
+	}
+	return 0, verifier.err
+}
+
+// fill reads the next chunk, releasing the previously withheld one, or releases the last chunk once verified
+func (verifier *VerifyingReader) fill() {
+	if verifier.verified {
+		verifier.ready, verifier.heldLen = verifier.buffers[verifier.held][:verifier.heldLen], 0
+		verifier.err = io.EOF
+		return
+	}
+	if verifier.buffers[0] == nil {
+		verifier.buffers = [2][]byte{make([]byte, verifyChunkSize), make([]byte, verifyChunkSize)}
+	}
+	var next = 1 - verifier.held
+	var length, err = verifier.reader.Read(verifier.buffers[next])
+	verifier.hasher.Write(verifier.buffers[next][:length])
+	if length > 0 {
+		verifier.ready = verifier.buffers[verifier.held][:verifier.heldLen]
+		verifier.held, verifier.heldLen = next, length
+	}
+	if err == io.EOF {
+		if err = verifier.verify(); err == nil {
+			verifier.verified = true
+		} else {
+			zeroizeBytes(verifier.buffers[verifier.held]) // The tampered end is never released
+		}
+	}
+	verifier.err = err
+}
+
+// verify finalizes the hasher and compares its digest with the expected one in constant time
+func (verifier *VerifyingReader) verify() error {
+	var actual = verifier.hasher.SumInto(make([]byte, verifier.hasher.Size()))
+	if subtle.ConstantTimeCompare(actual, verifier.expected) == 1 {
+		return nil
+	}
+	return &IntegrityError{HashAlgorithm: verifier.hasher.HashAlgorithm(), Expected: verifier.expected, Actual: actual}
+}
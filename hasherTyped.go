@@ -0,0 +1,142 @@
+package hasher
+
+import "reflect"
+
+// Digest is the constraint satisfied by every digest array type, so TypedHasher can return D directly
+type Digest interface {
+	~[20]byte | ~[24]byte | ~[28]byte | ~[32]byte | ~[48]byte | ~[64]byte
+}
+
+// Distinct digest types per HashAlgorithm; e.g. a Sha512t256Digest cannot be stored where a Sha256Digest is expected
+type (
+	Sha224Digest       [28]byte
+	Sha256Digest       [32]byte
+	Sha384Digest       [48]byte
+	Sha512Digest       [64]byte
+	Sha512t224Digest   [28]byte
+	Sha512t256Digest   [32]byte
+	Ripemd160Digest    [20]byte
+	Blake2bDigest      [64]byte
+	Blake2sDigest      [32]byte
+	Sha256t192Digest   [24]byte
+	Shake256t192Digest [24]byte
+)
+
+// TypedHasher wraps a Hasher so that Sum and InterimSum return the concrete digest type D
+type TypedHasher[D Digest] struct {
+	hasher Hasher
+}
+
+// NewTyped constructs a TypedHasher for hashAlgorithm; the size of D must match the algorithm's digest size
+func NewTyped[D Digest](hashAlgorithm HashAlgorithm) *TypedHasher[D] {
+	var hasher = New(hashAlgorithm)
+	if hasher == nil {
+		return nil
+	}
+	var digest D
+	if len(digest) != hasher.Size() {
+		LogFatal("NewTyped() digest type size does not match the hashAlgorithm")
+		return nil
+	}
+	return &TypedHasher[D]{hasher: hasher}
+}
+
+// NewSha224 constructs a SHA-224 TypedHasher
+func NewSha224() *TypedHasher[Sha224Digest] {
+	return NewTyped[Sha224Digest](Sha224)
+}
+
+// NewSha256 constructs a SHA-256 TypedHasher
+func NewSha256() *TypedHasher[Sha256Digest] {
+	return NewTyped[Sha256Digest](Sha256)
+}
+
+// NewSha384 constructs a SHA-384 TypedHasher
+func NewSha384() *TypedHasher[Sha384Digest] {
+	return NewTyped[Sha384Digest](Sha384)
+}
+
+// NewSha512 constructs a SHA-512 TypedHasher
+func NewSha512() *TypedHasher[Sha512Digest] {
+	return NewTyped[Sha512Digest](Sha512)
+}
+
+// NewSha512t224 constructs a SHA-512/224 TypedHasher
+func NewSha512t224() *TypedHasher[Sha512t224Digest] {
+	return NewTyped[Sha512t224Digest](Sha512t224)
+}
+
+// NewSha512t256 constructs a SHA-512/256 TypedHasher
+func NewSha512t256() *TypedHasher[Sha512t256Digest] {
+	return NewTyped[Sha512t256Digest](Sha512t256)
+}
+
+// NewRipemd160 constructs a RIPEMD-160 TypedHasher
+func NewRipemd160() *TypedHasher[Ripemd160Digest] {
+	return NewTyped[Ripemd160Digest](Ripemd160)
+}
+
+// NewBlake2b constructs a BLAKE2b-512 TypedHasher
+func NewBlake2b() *TypedHasher[Blake2bDigest] {
+	return NewTyped[Blake2bDigest](Blake2b)
+}
+
+// NewBlake2s constructs a BLAKE2s-256 TypedHasher
+func NewBlake2s() *TypedHasher[Blake2sDigest] {
+	return NewTyped[Blake2sDigest](Blake2s)
+}
+
+// NewSha256t192 constructs a SHA-256/192 TypedHasher
+func NewSha256t192() *TypedHasher[Sha256t192Digest] {
+	return NewTyped[Sha256t192Digest](Sha256t192)
+}
+
+// NewShake256t192 constructs a SHAKE256/192 TypedHasher
+func NewShake256t192() *TypedHasher[Shake256t192Digest] {
+	return NewTyped[Shake256t192Digest](Shake256t192)
+}
+
+// Copy returns a deep copy
+func (typed *TypedHasher[D]) Copy() *TypedHasher[D] {
+	return &TypedHasher[D]{hasher: typed.hasher.Copy()}
+}
+
+// Hasher returns the underlying (untyped) Hasher
+func (typed *TypedHasher[D]) Hasher() Hasher {
+	return typed.hasher
+}
+
+// InterimSum returns "the sum so far" without finalizing the original hasher
+func (typed *TypedHasher[D]) InterimSum() D {
+	return typedDigest[D](typed.hasher.InterimSum())
+}
+
+// Reset returns the hasher to the algorithm's IV so it can be reused
+func (typed *TypedHasher[D]) Reset() *TypedHasher[D] {
+	typed.hasher.Reset()
+	return typed
+}
+
+// Sum returns the final sum and marks the hasher as finished to prevent additional writes
+func (typed *TypedHasher[D]) Sum() D {
+	return typedDigest[D](typed.hasher.Sum())
+}
+
+// Write pushes additional data into the hasher; can be called multiple times in streaming applications
+func (typed *TypedHasher[D]) Write(message []byte) *TypedHasher[D] {
+	typed.hasher.Write(message)
+	return typed
+}
+
+// WriteBits pushes the leftmost bitLength bits of message into the hasher
+func (typed *TypedHasher[D]) WriteBits(message []byte, bitLength uint64) *TypedHasher[D] {
+	typed.hasher.WriteBits(message, bitLength)
+	return typed
+}
+
+// typedDigest converts an untyped sum (a byte array of the same length) to D
+func typedDigest[D Digest](sum interface{}) D {
+	var digest D
+	reflect.Copy(reflect.ValueOf(&digest).Elem(), reflect.ValueOf(sum))
+	return digest
+}
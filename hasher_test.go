@@ -1,14 +1,29 @@
 package hasher_test
 
 import (
+	"bytes"
+	"context"
+	"crypto"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	. "hasher"
+	"io"
+	"math/big"
 	"math/rand" // Repeatable is good
+	"os"
+	"path/filepath"
 	"reflect"
+	"runtime"
 	"runtime/debug"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"time"
 )
 
 func assertEquals(t *testing.T, expected interface{}, actual interface{}, message interface{}) {
@@ -85,38 +100,38 @@ func ExampleSha512t256_Copy() {
 
 func ExampleSha224_HashAlgorithm() {
 	var instance = New(Sha224)
-	fmt.Printf("Hash algorithm is (enumerated): %v", instance.HashAlgorithm())
-	// Output: Hash algorithm is (enumerated): 1
+	fmt.Printf("Hash algorithm is: %v", instance.HashAlgorithm())
+	// Output: Hash algorithm is: SHA-224
 }
 
 func ExampleSha256_HashAlgorithm() {
 	var instance = New(Sha256)
-	fmt.Printf("Hash algorithm is (enumerated): %v", instance.HashAlgorithm())
-	// Output: Hash algorithm is (enumerated): 2
+	fmt.Printf("Hash algorithm is: %v", instance.HashAlgorithm())
+	// Output: Hash algorithm is: SHA-256
 }
 
 func ExampleSha384_HashAlgorithm() {
 	var instance = New(Sha384)
-	fmt.Printf("Hash algorithm is (enumerated): %v", instance.HashAlgorithm())
-	// Output: Hash algorithm is (enumerated): 3
+	fmt.Printf("Hash algorithm is: %v", instance.HashAlgorithm())
+	// Output: Hash algorithm is: SHA-384
 }
 
 func ExampleSha512_HashAlgorithm() {
 	var instance = New(Sha512)
-	fmt.Printf("Hash algorithm is (enumerated): %v", instance.HashAlgorithm())
-	// Output: Hash algorithm is (enumerated): 4
+	fmt.Printf("Hash algorithm is: %v", instance.HashAlgorithm())
+	// Output: Hash algorithm is: SHA-512
 }
 
 func ExampleSha512t224_HashAlgorithm() {
 	var instance = New(Sha512t224)
-	fmt.Printf("Hash algorithm is (enumerated): %v", instance.HashAlgorithm())
-	// Output: Hash algorithm is (enumerated): 5
+	fmt.Printf("Hash algorithm is: %v", instance.HashAlgorithm())
+	// Output: Hash algorithm is: SHA-512/224
 }
 
 func ExampleSha512t256_HashAlgorithm() {
 	var instance = New(Sha512t256)
-	fmt.Printf("Hash algorithm is (enumerated): %v", instance.HashAlgorithm())
-	// Output: Hash algorithm is (enumerated): 6
+	fmt.Printf("Hash algorithm is: %v", instance.HashAlgorithm())
+	// Output: Hash algorithm is: SHA-512/256
 }
 
 func ExampleSha224_InterimSum() {
@@ -233,6 +248,56 @@ func ExampleSha512t256_Sum() {
 	// Output: Sum: [129 220 114 216 230 50 53 182 207 210 10 169 255 3 69 60 90 107 243 87 155 217 198 148 241 175 168 75 224 23 8 77]
 }
 
+func ExampleRipemd160_Sum() {
+	var instance = New(Ripemd160).Write([]byte("Message goes here"))
+	fmt.Printf("Sum: %v", instance.Sum())
+	// Output: Sum: [23 245 45 87 41 249 250 84 47 187 70 239 5 134 217 44 255 188 3 112]
+}
+
+func ExampleBlake2b_Sum() {
+	var instance = New(Blake2b).Write([]byte("Message goes here"))
+	fmt.Printf("Sum: %v", instance.Sum())
+	// Output: Sum: [50 255 86 226 169 132 14 166 168 138 88 187 89 154 244 153 170 172 118 65 57 68 172 63 174 244 253 35 26 255 39 104 64 5 102 206 24 78 118 134 185 136 63 195 223 63 231 92 139 178 10 54 28 101 112 208 179 240 2 152 98 152 166 127]
+}
+
+func ExampleBlake2s_Sum() {
+	var instance = New(Blake2s).Write([]byte("Message goes here"))
+	fmt.Printf("Sum: %v", instance.Sum())
+	// Output: Sum: [138 35 16 45 34 42 240 103 63 141 149 189 141 217 145 112 218 40 50 147 101 74 215 122 106 157 164 102 233 224 29 225]
+}
+
+func ExampleNewBlake2() {
+	var instance = NewBlake2(Blake2b, Blake2Parameters{Size: 20, Key: []byte("secret"),
+		Salt: []byte("saltsalt"), Personalization: []byte("MyApp v1")}).
+		Write([]byte("Message goes here"))
+	fmt.Printf("Sum: %x", instance.Sum())
+	// Output: Sum: 39b99fed57dafc9f6ee7b556cdc082c2c21816ba
+}
+
+func ExampleSha256t192_Sum() {
+	var instance = New(Sha256t192).Write([]byte("Message goes here"))
+	fmt.Printf("Sum: %v", instance.Sum())
+	// Output: Sum: [64 18 6 183 163 155 254 15 125 66 40 52 186 79 155 25 136 52 48 45 167 36 171 165]
+}
+
+func ExampleShake256t192_Sum() {
+	var instance = New(Shake256t192).Write([]byte("Message goes here"))
+	fmt.Printf("Sum: %v", instance.Sum())
+	// Output: Sum: [45 50 29 214 23 51 112 63 86 230 127 130 228 182 132 151 3 232 126 194 66 117 20 131]
+}
+
+func ExampleHash160() {
+	var publicKey, _ = hex.DecodeString("0250863ad64a87ae8a2fe83c1af1a8403cb53f53e486d8511dad8a04887e5b2352")
+	fmt.Printf("Hash160: %x", Hash160(publicKey))
+	// Output: Hash160: f54a5851e9372b87810a8e60cdd2e7cfd80b6e31
+}
+
+func ExampleSha256_WriteBits() {
+	var instance = New(Sha256).WriteBits([]byte{0x68}, 5) // The five bits 01101
+	fmt.Printf("Sum: %x", instance.Sum())
+	// Output: Sum: d6d3e02a31a84a8caa9718ed6c2057be09db45e7823eb5079ce7a573a3760f95
+}
+
 func ExampleSha224_Write() {
 	var instance = New(Sha224).
 		Write([]byte("Message goes here"))
@@ -275,6 +340,31 @@ func ExampleSha512t256_Write() {
 	// Output: Sum: [129 220 114 216 230 50 53 182 207 210 10 169 255 3 69 60 90 107 243 87 155 217 198 148 241 175 168 75 224 23 8 77]
 }
 
+func ExampleNewFor() {
+	var instance = NewFor(HMAC, Sha256)
+	fmt.Println(instance.Approved(), New(Ripemd160).Approved())
+	// Output: true false
+}
+
+func ExampleSha256_VerifyHex() {
+	var received = "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad"
+	fmt.Println(New(Sha256).Write([]byte("abc")).VerifyHex(received))
+	// Output: true
+}
+
+func ExampleParseHashAlgorithm() {
+	var hashAlgorithm, _ = ParseHashAlgorithm("sha2-512/256")
+	var info, _ = hashAlgorithm.Info()
+	fmt.Println(hashAlgorithm, info.OID, info.CollisionStrength)
+	// Output: SHA-512/256 2.16.840.1.101.3.4.2.6 128
+}
+
+func ExampleNewSha384() {
+	var digest Sha384Digest = NewSha384().Write([]byte("abc")).Sum() // A Sha256Digest here would not compile
+	fmt.Printf("%x", digest[:8])
+	// Output: cb00753f45a35e8b
+}
+
 //
 // Functional tests
 //
@@ -543,6 +633,322 @@ func TestSha512t256_Sum_Medium_Combos(t *testing.T) {
 	}
 }
 
+func TestRipemd160_Sum_ReferenceVectors(t *testing.T) {
+	// From https://homes.esat.kuleuven.be/~bosselae/ripemd160.html
+	var testCases = []struct{ message, digest string }{
+		{"", "9c1185a5c5e9fc54612808977ee8f548b2258d31"},
+		{"a", "0bdc9d2d256b3ee9daae347be6f4dc835a467ffe"},
+		{"abc", "8eb208f7e05d987a9b044a8e98c6b087f15a0bfc"},
+		{"message digest", "5d0689ef49d2fae572b881b123a85ffa21595f36"},
+		{"abcdefghijklmnopqrstuvwxyz", "f71c27109c692c1b56bbdceb5b9d2865b3708dbc"},
+		{"abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq", "12a053384a9c0c88e405a06c27dcf49ada62eb2b"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "b0e20b6e3116640286ed3a87a5713079b21f5189"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890",
+			"9b752e45573d4b39f4dbd3323cab82bf63326bfb"},
+	}
+	for _, tt := range testCases {
+		actual := New(Ripemd160).Write([]byte(tt.message)).Sum().([20]byte)
+		assertEquals(t, tt.digest, hex.EncodeToString(actual[:]), fmt.Sprintf("message=%v", tt.message))
+	}
+
+	// One million times "a", written in uneven segments
+	var instance = New(Ripemd160)
+	var segment = make([]byte, 999)
+	for index := range segment {
+		segment[index] = 'a'
+	}
+	for remaining := 1000000; remaining > 0; remaining -= len(segment) {
+		if remaining < len(segment) {
+			segment = segment[:remaining]
+		}
+		instance.Write(segment)
+	}
+	actual := instance.Sum().([20]byte)
+	assertEquals(t, "52783243c1697bdbe16d37f97f68f08325dc1528", hex.EncodeToString(actual[:]), "million a")
+}
+
+func TestRipemd160_Sum_Medium_Combos(t *testing.T) {
+	for length1 := 40; length1 < 340; length1 = length1 + 4 {
+		for length2 := 40; length2 < 120; length2 = length2 + 4 {
+			message1 := make([]byte, length1)
+			rand.Read(message1)
+			message2 := make([]byte, length2)
+			rand.Read(message2)
+			actual := New(Ripemd160).Write([]byte(message1)).Write(message2).Sum()
+			expected := New(Ripemd160).Write(append(message1, message2...)).Sum()
+			assertEquals(t, expected, actual, fmt.Sprintf("length=%v / %v", length1, length2))
+		}
+	}
+}
+
+func TestBlake2_Sum_RFC7693(t *testing.T) {
+	// From RFC 7693 appendices A and B
+	actual := New(Blake2b).Write([]byte("abc")).Sum().([64]byte)
+	assertEquals(t, "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d1"+
+		"7d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923", hex.EncodeToString(actual[:]), "BLAKE2b")
+	actualS := New(Blake2s).Write([]byte("abc")).Sum().([32]byte)
+	assertEquals(t, "508c5e8c327c14e2e1a72ba34eeb452f37458b209ed63a294d999b4c86675982",
+		hex.EncodeToString(actualS[:]), "BLAKE2s")
+}
+
+func TestBlake2_Sum_KeyedKAT(t *testing.T) {
+	// Entries from the reference blake2b-kat.txt / blake2s-kat.txt: key = 00 01 02..., in = 00 01 02... (length)
+	var testCases = []struct {
+		hashAlgorithm HashAlgorithm
+		length        int
+		digest        string
+	}{
+		{Blake2b, 0, "10ebb67700b1868efb4417987acf4690ae9d972fb7a590c2f02871799aaa4786b5e996e8f0f4eb981fc214b005f42d2ff4233499391653df7aefcbc13fc51568"},
+		{Blake2b, 1, "961f6dd1e4dd30f63901690c512e78e4b45e4742ed197c3c5e45c549fd25f2e4187b0bc9fe30492b16b0d0bc4ef9b0f34c7003fac09a5ef1532e69430234cebd"},
+		{Blake2b, 127, "76d2d819c92bce55fa8e092ab1bf9b9eab237a25267986cacf2b8ee14d214d730dc9a5aa2d7b596e86a1fd8fa0804c77402d2fcd45083688b218b1cdfa0dcbcb"},
+		{Blake2b, 128, "72065ee4dd91c2d8509fa1fc28a37c7fc9fa7d5b3f8ad3d0d7a25626b57b1b44788d4caf806290425f9890a3a2a35a905ab4b37acfd0da6e4517b2525c9651e4"},
+		{Blake2b, 129, "64475dfe7600d7171bea0b394e27c9b00d8e74dd1e416a79473682ad3dfdbb706631558055cfc8a40e07bd015a4540dcdea15883cbbf31412df1de1cd4152b91"},
+		{Blake2b, 255, "142709d62e28fcccd0af97fad0f8465b971e82201dc51070faa0372aa43e92484be1c1e73ba10906d5d1853db6a4106e0a7bf9800d373d6dee2d46d62ef2a461"},
+		{Blake2s, 0, "48a8997da407876b3d79c0d92325ad3b89cbb754d86ab71aee047ad345fd2c49"},
+		{Blake2s, 1, "40d15fee7c328830166ac3f918650f807e7e01e177258cdc0a39b11f598066f1"},
+		{Blake2s, 63, "c65382513f07460da39833cb666c5ed82e61b9e998f4b0c4287cee56c3cc9bcd"},
+		{Blake2s, 64, "8975b0577fd35566d750b362b0897a26c399136df07bababbde6203ff2954ed4"},
+		{Blake2s, 65, "21fe0ceb0052be7fb0f004187cacd7de67fa6eb0938d927677f2398c132317a8"},
+		{Blake2s, 255, "3fb735061abc519dfe979e54c1ee5bfad0a9d858b3315bad34bde999efd724dd"},
+	}
+	for _, tt := range testCases {
+		var key, message = make([]byte, 64), make([]byte, tt.length)
+		for index := range key {
+			key[index] = byte(index)
+		}
+		for index := range message {
+			message[index] = byte(index)
+		}
+		if tt.hashAlgorithm == Blake2s {
+			key = key[:32]
+		}
+		var instance = NewBlake2(tt.hashAlgorithm, Blake2Parameters{Key: key})
+		for index := range message { // Byte-by-byte to exercise the deferred final block
+			instance.Write(message[index : index+1])
+		}
+		assertEquals(t, tt.digest, fmt.Sprintf("%x", instance.Sum()), fmt.Sprintf("%v length=%v",
+			tt.hashAlgorithm, tt.length))
+	}
+}
+
+func TestBlake2_Sum_Parameters(t *testing.T) {
+	var instance = NewBlake2(Blake2s, Blake2Parameters{Size: 16, Key: []byte("secret"), Salt: []byte("salt"),
+		Personalization: []byte("MyApp")})
+	var partial = instance.Write([]byte("Message ")).Copy() // Copy must carry the parameters along
+	assertEquals(t, "22612bc11ccd922fbee177250c96dd97", fmt.Sprintf("%x",
+		partial.Write([]byte("goes here")).Sum()), "BLAKE2s parameters")
+	assertEquals(t, partial.Sum(), instance.Write([]byte("goes here")).InterimSum(), "BLAKE2s copy")
+	_, ok := instance.Sum().([16]byte)
+	assertEquals(t, true, ok, "BLAKE2s digest type")
+}
+
+func TestBlake2_Sum_Medium_Combos(t *testing.T) {
+	for _, hashAlgorithm := range []HashAlgorithm{Blake2b, Blake2s} {
+		for length1 := 40; length1 < 340; length1 = length1 + 4 {
+			for length2 := 40; length2 < 120; length2 = length2 + 4 {
+				message1 := make([]byte, length1)
+				rand.Read(message1)
+				message2 := make([]byte, length2)
+				rand.Read(message2)
+				actual := New(hashAlgorithm).Write([]byte(message1)).Write(message2).Sum()
+				expected := New(hashAlgorithm).Write(append(message1, message2...)).Sum()
+				assertEquals(t, expected, actual, fmt.Sprintf("length=%v / %v", length1, length2))
+			}
+		}
+	}
+}
+
+func TestSha256t192_Sum_Medium_Singles(t *testing.T) {
+	for length := 0; length < 340; length++ {
+		message := make([]byte, length)
+		rand.Read(message)
+		actual := New(Sha256t192).Write([]byte(message)).Sum().([24]byte)
+		expected := sha256.Sum256([]byte(message)) // SP 800-208: the leftmost 192 bits of SHA-256
+		assertEquals(t, hex.EncodeToString(expected[:24]), hex.EncodeToString(actual[:]),
+			fmt.Sprintf("length=%v", length))
+		sha224Sum := sha256.Sum224([]byte(message)) // Different IV, so never a prefix of SHA-224
+		assertEquals(t, false, hex.EncodeToString(sha224Sum[:24]) == hex.EncodeToString(actual[:]),
+			fmt.Sprintf("length=%v", length))
+	}
+}
+
+func TestShake256t192_Sum_Vectors(t *testing.T) {
+	// The leftmost 192 bits of SHAKE256 output; lengths straddle the 136 byte rate
+	var testCases = []struct {
+		message []byte
+		digest  string
+	}{
+		{[]byte(""), "46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82"},
+		{[]byte("abc"), "483366601360a8771c6863080cc4114d8db44530f8f1e1ee"},
+		{[]byte("abcdbcdecdefdefgefghfghighijhijkijkljklmklmnlmnomnopnopq"),
+			"4d8c2dd2435a0128eefbb8c36f6f87133a7911e18d979ee1"},
+		{make([]byte, 135), "4a6c0970c326babfaeef17f91988d1b4c5e95ed584c21b55"},
+		{make([]byte, 136), "ea947b835fec1f9b0a7eabba901deb7881fd9999a1cbd5cc"},
+		{make([]byte, 137), "60691a6b6b79c4abf99438b3f7a6455f2ce44fed8c8546cc"},
+	}
+	for _, tt := range testCases {
+		actual := New(Shake256t192).Write(tt.message).Sum().([24]byte)
+		assertEquals(t, tt.digest, hex.EncodeToString(actual[:]), fmt.Sprintf("length=%v", len(tt.message)))
+	}
+}
+
+func TestSp800208_Sum_Vectors(t *testing.T) {
+	// Hash inputs shaped as in the n = 24 LMS and XMSS parameter sets of SP 800-208. The expected digests were
+	// computed independently with crypto/sha256 (truncated) and crypto/sha3 SHAKE256, not taken from NIST.
+	var testCases = []struct {
+		name       string
+		message    string
+		sha256t192 string
+		shake256   string
+	}{
+		{"LMS leaf: I || u32str(r) || D_LEAF || K",
+			"000102030405060708090a0b0c0d0e0f000000208282404142434445464748494a4b4c4d4e4f5051525354555657",
+			"2169813f1d8ce15600422e7760de99f4a31f8e20d9f48191", "0e9613b9248567fd69700129eef13a7a950088dd35f77105"},
+		{"LM-OTS chain: I || u32str(q) || u16str(i) || u8str(j) || tmp",
+			"000102030405060708090a0b0c0d0e0f00000005000703808182838485868788898a8b8c8d8e8f9091929394959697",
+			"2010616dd73fc36ac9faf79f7210409fce961510db730ab3", "30f021b820a67dc718d2d4eacd78bd6065b58138d1bdb4ae"},
+		{"XMSS F: toByte(0, 4) || KEY || M",
+			"00000000a0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7c0c1c2c3c4c5c6c7c8c9cacbcccdcecfd0d1d2d3d4d5d6d7",
+			"0456e8207e0880be17a3db381113480f87292a606e3e665d", "aaedb464458126f80a8e74f6945a168a8decdf5656cad84f"},
+		{"XMSS PRF: toByte(3, 4) || KEY || ADRS",
+			"00000003202122232425262728292a2b2c2d2e2f3031323334353637000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"1dafd5facade13b2837917e48a1a0c31561512505e8512bf", "038f9f54211a29ff562b248d5db1ab08a13d8db10d3b3a05"},
+	}
+	for _, tt := range testCases {
+		var message, _ = hex.DecodeString(tt.message)
+		assertEquals(t, tt.sha256t192, fmt.Sprintf("%x", New(Sha256t192).Write(message).Sum()), "SHA-256/192 "+tt.name)
+		assertEquals(t, tt.shake256, fmt.Sprintf("%x", New(Shake256t192).Write(message).Sum()), "SHAKE256/192 "+tt.name)
+	}
+}
+
+func TestShake256t192_Sum_Medium_Combos(t *testing.T) {
+	for length1 := 40; length1 < 340; length1 = length1 + 4 {
+		for length2 := 40; length2 < 120; length2 = length2 + 4 {
+			message1 := make([]byte, length1)
+			rand.Read(message1)
+			message2 := make([]byte, length2)
+			rand.Read(message2)
+			actual := New(Shake256t192).Write([]byte(message1)).Write(message2).Sum()
+			expected := New(Shake256t192).Write(append(message1, message2...)).Sum()
+			assertEquals(t, expected, actual, fmt.Sprintf("length=%v / %v", length1, length2))
+		}
+	}
+}
+
+func TestWriteBits_PartialBytes(t *testing.T) {
+	// Message bytes are (index*37 + 11) mod 256; lengths straddle the padding and block boundaries
+	var message = make([]byte, 200)
+	for index := range message {
+		message[index] = byte(index*37 + 11)
+	}
+	var testCases = []struct {
+		hashAlgorithm HashAlgorithm
+		bitLength     uint64
+		digest        string
+	}{
+		{Sha224, 1, "d3fe57cb76cdd24e9eb23e7e15684e039c75459beaae100f89712e9d"},
+		{Sha224, 5, "32f7a1609adcf81c6cc150fe6161d383b40b41d9081b2c9f5e881060"},
+		{Sha224, 447, "db41803b48dacb2deb05c66a7417da3f1e893e4ab6d61118f4cd863e"},
+		{Sha224, 451, "cd60f4cc696c1bcb11084417991df59fd0f8daeac8b06c11242bc4f1"},
+		{Sha224, 511, "dda1a2ee2ea892fdb9530ca09f2e714a15790a23e064819e04cb49cc"},
+		{Sha224, 1021, "d27f2fc4c4af3edd5aa28a9366ed90344b4f2b6ace65524e4a2340bd"},
+		{Sha256, 1, "bd4f9e98beb68c6ead3243b1b4c7fed75fa4feaab1f84795cbd8a98676a2a375"},
+		{Sha256, 5, "18a911498e1f684d15c187d035e0ab18e46f373cbe25469ddb808a33cef2c00b"},
+		{Sha256, 447, "debb15d7c8a946e6ab159390ebec6b1a166ebd214c0516fcb13506d66ec7adad"},
+		{Sha256, 451, "9b0300f229834d8c78e69246f7aaefdcc07c4040a5662e31ab3bd22d6d600253"},
+		{Sha256, 511, "41c9cd8823c1fe7dda3fb8ea9430552b77a7161cd8bb9d8a6d5890af3edeac41"},
+		{Sha256, 1021, "329b992e9eccc26eca0db9adc2e2ab20a1623cd4ce2124c7b153e878df42356c"},
+		{Sha384, 1, "634aa63038a164ae6c7d48b319f2aca0a107908e548519204c6d72dbeac0fdc3c9246674f98e8fd30221ba986e737d61"},
+		{Sha384, 5, "fc3630e60f9ac106c3b9bec2328775572e0b80383272a9cd18cdb83016978e7514e8e1c250f5ac30253523ec2cc2e645"},
+		{Sha384, 895, "8ce7363a4b091c607b1a5821a55a73223d234d39a5d1a7b98dee04aa87e2cd27d153109c9ce33342c4e82e0d5bbc9a67"},
+		{Sha384, 899, "c560506b20b33928a36ae87c75b2e0f782f5604909d653e958fcb64d5f26f1836bfaebb147e858784f0349021ab74e18"},
+		{Sha384, 1023, "dc289cbb2d0091c4c013fcf1a6f0c0149184bb7ca6fd1bafb8d3e1ccf53e92b3f218a3a8b39ecb25150e84d6796e053e"},
+		{Sha384, 1533, "7b82a3ea51e3c78867b02900bdacf639034ef7ccd7af49042b7f23b43985c16d0a69feceaf0376f13d570262ae7113ab"},
+		{Sha512, 1, "b4594eb12959fc2e6979b6783554299cc0369f44083a8b0955baefd8830cda22894b0b46c0ed49490e391ad99af856cc1bd96f238c7f2a17cf37aeb7e793395a"},
+		{Sha512, 5, "76e91eeaf70db5d7f1abc7832eef568421908d50fe2cbb9ec2e7f2ce381f2fa9c2c3072489ce7e68595b943a07e2dfc502ab1a0c523b4c3db20921e47f1ad6d2"},
+		{Sha512, 895, "f68fe1f169575909d7edecdafbb1400faa27a394fe06d59552e5137318955dad340a209c2f863c7ccc050a158b8b68ab507d66f50edd09f18a4d5b77c08ad307"},
+		{Sha512, 899, "f91fe00c7b692a1e143dbe4f4eb00fd8762a35205bbaa39305f96bdd7aa861c3f767411da8835857e23e29a8f642547aea117e337708d9179834162ab9f4b893"},
+		{Sha512, 1023, "9765594ebfec45973d0cf8413da8551a84c6db3630814d7e2d8f4b0e924984d8449fa4e91165b83bd5d2e969c48f6beb03cfa473f878142c386a7c58385c25e1"},
+		{Sha512, 1533, "27c23879ccadea9a936cb00459b3ecef90f59b03b74e645c3c5f86280b41c9cf743a2126e237f6bd425f2335f734be37331a2ca89cb537795ee4db9893e0c630"},
+		{Sha512t224, 1, "5cb2c91954ab4fc72c555fd379268bf272782516ec5da0660c421dd1"},
+		{Sha512t224, 5, "1f555ceed3f5d4018fa43d4f0078ef69c2044be29c00e1b174f01c5b"},
+		{Sha512t224, 895, "0332c9930cf021b005a1695c3ce97d4cae8d0bd8fb000a732a3e4886"},
+		{Sha512t224, 899, "21a5ca4d0bb80116657a73f0bfdab45b4f4f446225ca1f5270d71804"},
+		{Sha512t224, 1023, "d182b0371eda7ae242cc4569919f5b6f889caed175e6af9e4f14d5e3"},
+		{Sha512t224, 1533, "9aae694dbf12a2fef06b2d6d2414c443b8925567d744fd454fe5b6e7"},
+		{Sha512t256, 1, "d2a8cc81374ea74aa3d9e4cd62a5c5bc7a0dc516399855300cb90b0c2960dd1e"},
+		{Sha512t256, 5, "a5e2be0f200211ae5dffeafa52c8f356aa0a428c003db4f4cb69372bc430370c"},
+		{Sha512t256, 895, "362bbf13ffa491152a3f1bdba37d9e6e678c6a5fde209f587bc701f9aaf9d87d"},
+		{Sha512t256, 899, "490c6945ac1d254e39f3dd22b181140edb20f54bdb6c9ec918d66fef17026649"},
+		{Sha512t256, 1023, "dc9b636796086d658c12fb6f2ef747aec19313ab6403e21e709a2a65a1b22369"},
+		{Sha512t256, 1533, "bae3dcf4d0254b838eb8297da31fc48e2cea0893c5d152c2ae1dece34c47dab3"},
+		{Sha256t192, 1, "bd4f9e98beb68c6ead3243b1b4c7fed75fa4feaab1f84795"},
+		{Sha256t192, 5, "18a911498e1f684d15c187d035e0ab18e46f373cbe25469d"},
+		{Sha256t192, 447, "debb15d7c8a946e6ab159390ebec6b1a166ebd214c0516fc"},
+		{Sha256t192, 451, "9b0300f229834d8c78e69246f7aaefdcc07c4040a5662e31"},
+		{Sha256t192, 511, "41c9cd8823c1fe7dda3fb8ea9430552b77a7161cd8bb9d8a"},
+		{Sha256t192, 1021, "329b992e9eccc26eca0db9adc2e2ab20a1623cd4ce2124c7"},
+	}
+	for _, tt := range testCases {
+		actual := New(tt.hashAlgorithm).Write(message[:tt.bitLength/16]).
+			WriteBits(message[tt.bitLength/16:], tt.bitLength-tt.bitLength/16*8).Sum()
+		assertEquals(t, tt.digest, fmt.Sprintf("%x", actual), fmt.Sprintf("%v bitLength=%v",
+			tt.hashAlgorithm, tt.bitLength))
+		actual = New(tt.hashAlgorithm).WriteBits(message, tt.bitLength).Copy().Sum() // Copy keeps partial bits
+		assertEquals(t, tt.digest, fmt.Sprintf("%x", actual), fmt.Sprintf("%v bitLength=%v (copy)",
+			tt.hashAlgorithm, tt.bitLength))
+	}
+}
+
+func TestWriteBits_WholeBytes(t *testing.T) {
+	var message = make([]byte, 300)
+	rand.Read(message)
+	for hashAlgorithm := Sha224; hashAlgorithm <= Shake256t192; hashAlgorithm++ {
+		for _, length := range []int{0, 55, 56, 64, 111, 112, 128, 300} {
+			actual := New(hashAlgorithm).WriteBits(message, uint64(length)*8).Sum()
+			expected := New(hashAlgorithm).Write(message[:length]).Sum()
+			assertEquals(t, expected, actual, fmt.Sprintf("%v length=%v", hashAlgorithm, length))
+		}
+	}
+}
+
+// base58Check encodes a versioned payload as a Bitcoin address (test helper only)
+func base58Check(version byte, payload []byte) string {
+	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	var data = append([]byte{version}, payload...)
+	var checksum = sha256.Sum256(data)
+	checksum = sha256.Sum256(checksum[:])
+	data = append(data, checksum[:4]...)
+	var number, remainder = new(big.Int).SetBytes(data), new(big.Int)
+	var encoded []byte
+	for number.Sign() > 0 {
+		number.DivMod(number, big.NewInt(58), remainder)
+		encoded = append([]byte{alphabet[remainder.Int64()]}, encoded...)
+	}
+	for index := 0; index < len(data) && data[index] == 0; index++ {
+		encoded = append([]byte{alphabet[0]}, encoded...)
+	}
+	return string(encoded)
+}
+
+func TestHash160_P2PKH(t *testing.T) {
+	var testCases = []struct{ publicKey, hash160, address string }{
+		{ // Example from https://en.bitcoin.it/wiki/Technical_background_of_version_1_Bitcoin_addresses
+			"0250863ad64a87ae8a2fe83c1af1a8403cb53f53e486d8511dad8a04887e5b2352",
+			"f54a5851e9372b87810a8e60cdd2e7cfd80b6e31", "1PMycacnJaSqwwJqjawXBErnLsZ7RkXUAs"},
+		{ // Genesis block coinbase output
+			"04678afdb0fe5548271967f1a67130b7105cd6a828e03909a67962e0ea1f61deb649f6bc3f4cef38c4f35504e51ec112" +
+				"de5c384df7ba0b8d578a4c702b6bf11d5f",
+			"62e907b15cbf27d5425399ebf6f0fb50ebb88f18", "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"},
+	}
+	for _, tt := range testCases {
+		publicKey, _ := hex.DecodeString(tt.publicKey)
+		actual := Hash160(publicKey)
+		assertEquals(t, tt.hash160, hex.EncodeToString(actual[:]), fmt.Sprintf("publicKey=%v", tt.publicKey))
+		assertEquals(t, tt.address, base58Check(0x00, actual[:]), fmt.Sprintf("publicKey=%v", tt.publicKey))
+	}
+}
+
 func TestFuzzEverything(t *testing.T) {
 
 	for iterations := 0; iterations < 10000; iterations++ {
@@ -564,7 +970,7 @@ func TestFuzzEverything(t *testing.T) {
 		message4 := make([]byte, length4)
 		rand.Read(message4)
 		message5 := make([]byte, length5)
-		rand.Read(message4)
+		rand.Read(message5)
 
 		bigMsg := append(append(append(append(message1, message2...), message3...), message4...), message5...)
 
@@ -600,6 +1006,913 @@ func TestFuzzEverything(t *testing.T) {
 	}
 }
 
+func TestSelfTest(t *testing.T) {
+	ResetSelfTest()
+	var results = SelfTest()
+	assertEquals(t, 12, len(results), "integrity self-check plus one KAT per HashAlgorithm")
+	assertEquals(t, "Integrity", results[0].Test, "integrity self-check runs first")
+	for _, result := range results {
+		assertEquals(t, true, result.Passed, fmt.Sprintf("%v %v: %v", result.Test, result.HashAlgorithm, result.Actual))
+	}
+	assertEquals(t, false, SelfTestErrorState(), "error state after passing self-tests")
+	assertEquals(t, len(results), len(SelfTestResults()), "recorded results")
+}
+
+func TestSelfTest_Modes(t *testing.T) {
+	defer func() { SelfTestSetting = PowerOnSelfTest }()
+	var testCases = []struct {
+		mode     SelfTestMode
+		expected int
+	}{
+		{PowerOnSelfTest, 12}, {ConditionalSelfTest, 1}, {ManualSelfTest, 0},
+	}
+	for _, tt := range testCases {
+		ResetSelfTest()
+		SelfTestSetting = tt.mode
+		New(Sha384).Write([]byte("abc")).Sum()
+		New(Sha384).Write([]byte("abc")).Sum()
+		assertEquals(t, tt.expected, len(SelfTestResults()), fmt.Sprintf("results for mode %v", tt.mode))
+	}
+}
+
+func TestSelfTest_Concurrent(t *testing.T) {
+	// Constructors racing on first use must run each due self-test once, then skip the lock
+	defer func() { SelfTestSetting = PowerOnSelfTest; ResetSelfTest() }()
+	for _, tt := range []struct {
+		mode     SelfTestMode
+		expected int
+	}{
+		{PowerOnSelfTest, 12}, {ConditionalSelfTest, 2},
+	} {
+		ResetSelfTest()
+		SelfTestSetting = tt.mode
+		var done = make(chan bool)
+		for worker := 0; worker < 8; worker++ {
+			go func(worker int) {
+				defer func() { done <- true }()
+				for i := 0; i < 20; i++ {
+					var hashAlgorithm = []HashAlgorithm{Sha256, Blake2s}[(worker+i)%2]
+					assertEquals(t, SelfTestVectors[hashAlgorithm], fmt.Sprintf("%x",
+						New(hashAlgorithm).Write([]byte("abc")).Sum()), fmt.Sprintf("%v in mode %v", hashAlgorithm,
+						tt.mode))
+				}
+			}(worker)
+		}
+		for worker := 0; worker < 8; worker++ {
+			<-done
+		}
+		assertEquals(t, tt.expected, len(SelfTestResults()), fmt.Sprintf("results for mode %v", tt.mode))
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	var before, after = time.Date(2030, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2031, 6, 1, 0, 0, 0, 0, time.UTC)
+	var testCases = []struct {
+		purpose       Purpose
+		hashAlgorithm HashAlgorithm
+		now           time.Time
+		permitted     bool
+		approved      bool
+	}{
+		{DigitalSignature, Sha224, before, true, true},
+		{DigitalSignature, Sha224, after, false, false},
+		{DigitalSignature, Sha256, after, true, true},
+		{DigitalSignature, Ripemd160, before, false, false},
+		{HMAC, Sha224, after, true, true},
+		{HMAC, Ripemd160, after, true, false},
+		{KeyDerivation, Blake2s, after, true, false},
+		{General, Sha256t192, after, true, false},
+		{HashBasedSignature, Sha256t192, after, true, true},
+		{HashBasedSignature, Shake256t192, after, true, true},
+		{HashBasedSignature, Sha256, after, true, true},
+	}
+	for _, tt := range testCases {
+		var err = SecurityPolicy.Check(tt.purpose, tt.hashAlgorithm, tt.now)
+		assertEquals(t, tt.permitted, err == nil, fmt.Sprintf("%v %v %v: %v", tt.purpose, tt.hashAlgorithm, tt.now, err))
+		assertEquals(t, tt.approved, SecurityPolicy.Approved(tt.purpose, tt.hashAlgorithm, tt.now),
+			fmt.Sprintf("%v %v %v approved", tt.purpose, tt.hashAlgorithm, tt.now))
+	}
+	assertEquals(t, 112, SecurityStrength(DigitalSignature, Sha224), "SHA-224 collision strength")
+	assertEquals(t, 224, SecurityStrength(HMAC, Sha224), "SHA-224 preimage strength")
+}
+
+func TestNewFor_Approved(t *testing.T) {
+	var testCases = []struct {
+		purpose       Purpose
+		hashAlgorithm HashAlgorithm
+		approved      bool
+	}{
+		{General, Sha256, true}, {DigitalSignature, Sha384, true}, {General, Ripemd160, false},
+		{General, Blake2b, false}, {General, Shake256t192, false}, {HashBasedSignature, Shake256t192, true},
+	}
+	for _, tt := range testCases {
+		var instance = NewFor(tt.purpose, tt.hashAlgorithm).Write([]byte("abc"))
+		assertEquals(t, tt.approved, instance.Approved(), fmt.Sprintf("%v %v", tt.purpose, tt.hashAlgorithm))
+		assertEquals(t, tt.approved, instance.Copy().Approved(), fmt.Sprintf("%v %v Copy", tt.purpose,
+			tt.hashAlgorithm))
+	}
+	assertEquals(t, false, NewBlake2(Blake2s, Blake2Parameters{Size: 16}).Approved(), "NewBlake2")
+}
+
+func TestDestroy(t *testing.T) {
+	var message = []byte(strings.Repeat("sensitive message bytes ", 9)) // Leaves a partial block pending
+	for hashAlgorithm := Sha224; hashAlgorithm <= Shake256t192; hashAlgorithm++ {
+		var instance = New(hashAlgorithm)
+		if hashAlgorithm == Blake2b || hashAlgorithm == Blake2s {
+			instance = NewBlake2(hashAlgorithm, Blake2Parameters{Key: []byte("secret key")})
+		}
+		instance.Write(message)
+		var memory = StateMemory(instance)
+		for index, buffer := range memory {
+			assertEquals(t, false, isZero(buffer), fmt.Sprintf("%v buffer %v holds data before Destroy",
+				hashAlgorithm, index))
+		}
+		instance.Destroy()
+		for index, buffer := range memory {
+			assertEquals(t, true, isZero(buffer), fmt.Sprintf("%v buffer %v wiped by Destroy", hashAlgorithm, index))
+		}
+	}
+}
+
+func TestDestroy_Concurrent(t *testing.T) {
+	// Destroying (or zeroizing on Sum) one hasher must not disturb others hashing meanwhile
+	var done = make(chan bool)
+	for worker := 0; worker < 4; worker++ {
+		go func(worker int) {
+			defer func() { done <- true }()
+			for i := 0; i < 50; i++ {
+				var hashAlgorithm = []HashAlgorithm{Sha256, Sha512}[(worker+i)%2]
+				var message = bMsg[i : i+1000+worker]
+				if worker%2 == 0 && i%2 == 0 {
+					New(hashAlgorithm).Write(message).Destroy()
+					continue
+				}
+				if worker%2 == 0 {
+					ZeroizeOnSum(New(hashAlgorithm)).Write(message).Sum()
+					continue
+				}
+				var expected = map[HashAlgorithm]string{Sha256: fmt.Sprintf("%x", sha256.Sum256(message)),
+					Sha512: fmt.Sprintf("%x", sha512.Sum512(message))}[hashAlgorithm]
+				assertEquals(t, expected, fmt.Sprintf("%x", New(hashAlgorithm).Write(message).Sum()),
+					fmt.Sprintf("%v digest while other hashers are destroyed", hashAlgorithm))
+			}
+		}(worker)
+	}
+	for worker := 0; worker < 4; worker++ {
+		<-done
+	}
+}
+
+func TestDestroy_Blake2Parameters(t *testing.T) {
+	// Salt and personalization are not secret: after Destroy, Reset must reproduce the parameterized digest
+	for _, hashAlgorithm := range []HashAlgorithm{Blake2b, Blake2s} {
+		var parameters = Blake2Parameters{Salt: []byte("salt"), Personalization: []byte("MyApp")}
+		var expected = NewBlake2(hashAlgorithm, parameters).Write([]byte("abc")).Sum()
+		var instance = NewBlake2(hashAlgorithm, parameters).Write([]byte("abc"))
+		instance.Destroy()
+		assertEquals(t, expected, instance.Reset().Write([]byte("abc")).Sum(), fmt.Sprintf("%v Reset after Destroy",
+			hashAlgorithm))
+	}
+}
+
+func TestZeroizeOnSum(t *testing.T) {
+	for hashAlgorithm := Sha224; hashAlgorithm <= Shake256t192; hashAlgorithm++ {
+		var other = New(hashAlgorithm).Write([]byte("abc"))
+		var instance = ZeroizeOnSum(New(hashAlgorithm)).Write([]byte("abc"))
+		var memory = StateMemory(instance)
+		var interim = instance.InterimSum() // Wipes only the copy
+		assertEquals(t, false, isZero(memory[1]), fmt.Sprintf("%v InterimSum left the original intact",
+			hashAlgorithm))
+		var sum = instance.Sum()
+		assertEquals(t, interim, sum, fmt.Sprintf("%v InterimSum", hashAlgorithm))
+		assertEquals(t, SelfTestVectors[hashAlgorithm], fmt.Sprintf("%x", sum), fmt.Sprintf("%v Sum",
+			hashAlgorithm))
+		for index, buffer := range memory {
+			assertEquals(t, true, isZero(buffer), fmt.Sprintf("%v buffer %v wiped by Sum", hashAlgorithm, index))
+		}
+		assertEquals(t, sum, other.Sum(), fmt.Sprintf("%v other hasher Sum", hashAlgorithm))
+		assertEquals(t, sum, other.Sum(), fmt.Sprintf("%v other hasher not zeroized", hashAlgorithm))
+	}
+}
+
+func TestCopy_WipesSerializedState(t *testing.T) {
+	var inspected []byte
+	InspectCopyBuffer(func(buffer []byte) { inspected = buffer })
+	defer InspectCopyBuffer(nil)
+	New(Sha256).Write([]byte("sensitive message bytes")).Copy()
+	assertEquals(t, true, len(inspected) > 0, "hasherCopy buffer inspected")
+	assertEquals(t, true, isZero(inspected), "hasherCopy buffer wiped")
+}
+
+// isZero reports whether every byte of buffer is zero
+func isZero(buffer []byte) bool {
+	for _, value := range buffer {
+		if value != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestVerify(t *testing.T) {
+	for hashAlgorithm := Sha224; hashAlgorithm <= Shake256t192; hashAlgorithm++ {
+		var expected, _ = hex.DecodeString(SelfTestVectors[hashAlgorithm])
+		var message = fmt.Sprint(hashAlgorithm)
+		assertEquals(t, true, New(hashAlgorithm).Write([]byte("abc")).Verify(expected), message)
+		assertEquals(t, true, New(hashAlgorithm).Write([]byte("abc")).VerifyHex(strings.ToUpper(
+			SelfTestVectors[hashAlgorithm])), message+" hex")
+		assertEquals(t, true, New(hashAlgorithm).Write([]byte("abc")).VerifyBase64(
+			base64.StdEncoding.EncodeToString(expected)), message+" base64")
+		assertEquals(t, false, New(hashAlgorithm).Write([]byte("abd")).Verify(expected), message+" mismatch")
+		expected[len(expected)-1] ^= 1
+		assertEquals(t, false, New(hashAlgorithm).Write([]byte("abc")).Verify(expected), message+" last bit")
+	}
+	var instance = NewBlake2(Blake2s, Blake2Parameters{Size: 20})
+	var sum = instance.Write([]byte("abc")).Sum().([20]byte)
+	assertEquals(t, true, instance.Verify(sum[:]), "BLAKE2s-160 after Sum")
+}
+
+func TestVerifyDigest(t *testing.T) {
+	var expected = New(Sha256).Write([]byte("abc")).Sum().([32]byte)
+	assertEquals(t, nil, VerifyDigest(New(Sha256).Write([]byte("abc")), expected[:]), "VerifyDigest match")
+	var err = VerifyDigest(New(Sha256).Write([]byte("abd")), expected[:])
+	var integrityError *IntegrityError
+	assertEquals(t, true, errors.As(err, &integrityError) && errors.Is(err, ErrIntegrity), fmt.Sprint(err))
+	assertEquals(t, fmt.Sprintf("%x", expected), fmt.Sprintf("%x", integrityError.Expected), "IntegrityError expected")
+	assertEquals(t, fmt.Sprintf("%x", New(Sha256).Write([]byte("abd")).Sum()), fmt.Sprintf("%x", integrityError.Actual),
+		"IntegrityError actual")
+
+	// A digest of another length names the algorithms that produce it
+	var sha384Sum = New(Sha384).Write([]byte("abc")).Sum().([48]byte)
+	err = VerifyDigest(New(Sha256).Write([]byte("abc")), sha384Sum[:])
+	assertEquals(t, true, errors.Is(err, ErrDigestLength), fmt.Sprint(err))
+	assertEquals(t, true, strings.Contains(err.Error(), "SHA-384"), err.Error())
+}
+
+func TestReset(t *testing.T) {
+	var testCases = []struct {
+		hashAlgorithm HashAlgorithm
+		size          int
+		blockSize     int
+	}{
+		{Sha224, 28, 64}, {Sha256, 32, 64}, {Sha384, 48, 128}, {Sha512, 64, 128}, {Sha512t224, 28, 128},
+		{Sha512t256, 32, 128}, {Ripemd160, 20, 64}, {Blake2b, 64, 128}, {Blake2s, 32, 64}, {Sha256t192, 24, 64},
+		{Shake256t192, 24, 136},
+	}
+	for _, tt := range testCases {
+		var instance = New(tt.hashAlgorithm)
+		assertEquals(t, tt.size, instance.Size(), fmt.Sprintf("%v Size", tt.hashAlgorithm))
+		assertEquals(t, tt.blockSize, instance.BlockSize(), fmt.Sprintf("%v BlockSize", tt.hashAlgorithm))
+		instance.Write(bMsg[:1000]).WriteBits(bMsg[:2], 16)
+		assertEquals(t, uint64(1002), instance.Len(), fmt.Sprintf("%v Len", tt.hashAlgorithm))
+		instance.Sum()
+		assertEquals(t, uint64(1002), instance.Len(), fmt.Sprintf("%v Len after Sum", tt.hashAlgorithm))
+		var sum = instance.Reset().Write([]byte("abc")).Sum()
+		assertEquals(t, SelfTestVectors[tt.hashAlgorithm], fmt.Sprintf("%x", sum), fmt.Sprintf("%v after Sum",
+			tt.hashAlgorithm))
+		instance.Reset().Write(bMsg[:77])
+		sum = instance.Reset().Write([]byte("abc")).Sum()
+		assertEquals(t, SelfTestVectors[tt.hashAlgorithm], fmt.Sprintf("%x", sum), fmt.Sprintf("%v mid-message",
+			tt.hashAlgorithm))
+		instance.Destroy()
+		sum = instance.Reset().Write([]byte("abc")).Sum()
+		assertEquals(t, SelfTestVectors[tt.hashAlgorithm], fmt.Sprintf("%x", sum), fmt.Sprintf("%v after Destroy",
+			tt.hashAlgorithm))
+		assertEquals(t, 0.0, testing.AllocsPerRun(10, func() { instance.Reset() }), fmt.Sprintf("%v allocations",
+			tt.hashAlgorithm))
+	}
+
+	// BLAKE2 keeps its parameters (size, key, salt and personalization) across Reset
+	var parameters = Blake2Parameters{Size: 20, Key: []byte("key"), Salt: []byte("salt"), Personalization: []byte("me")}
+	var keyed = NewBlake2(Blake2s, parameters)
+	var expected = keyed.Copy().Write([]byte("abc")).Sum()
+	keyed.Write(bMsg[:100]).Sum()
+	assertEquals(t, 20, keyed.Size(), "BLAKE2s-160 Size")
+	assertEquals(t, expected, keyed.Reset().Write([]byte("abc")).Sum(), "keyed BLAKE2s Reset")
+}
+
+func TestRegistry(t *testing.T) {
+	for hashAlgorithm := Sha224; hashAlgorithm <= Shake256t192; hashAlgorithm++ {
+		var info, ok = hashAlgorithm.Info()
+		assertEquals(t, true, ok, fmt.Sprintf("%v registered", uint32(hashAlgorithm)))
+		assertEquals(t, info.Name, hashAlgorithm.String(), "String")
+		assertEquals(t, New(hashAlgorithm).Size(), info.Size, fmt.Sprintf("%v Size", hashAlgorithm))
+		assertEquals(t, New(hashAlgorithm).BlockSize(), info.BlockSize, fmt.Sprintf("%v BlockSize", hashAlgorithm))
+		assertEquals(t, info.Size*4, info.CollisionStrength, fmt.Sprintf("%v CollisionStrength", hashAlgorithm))
+		if info.CryptoHash != 0 {
+			assertEquals(t, info.Size, info.CryptoHash.Size(), fmt.Sprintf("%v crypto.Hash", hashAlgorithm))
+			assertEquals(t, hashAlgorithm, HashAlgorithmFromCryptoHash(info.CryptoHash), "FromCryptoHash")
+		}
+		if len(info.OID) > 0 {
+			assertEquals(t, hashAlgorithm, HashAlgorithmFromOID(info.OID), "FromOID")
+		}
+		for _, name := range append([]string{info.Name, strings.ToLower(info.Name), info.OID.String()},
+			info.Aliases...) {
+			if name == "" {
+				continue
+			}
+			parsed, err := ParseHashAlgorithm(name)
+			assertEquals(t, hashAlgorithm, parsed, fmt.Sprintf("ParseHashAlgorithm(%q): %v", name, err))
+		}
+		encoded, _ := json.Marshal(struct{ Algorithm HashAlgorithm }{hashAlgorithm})
+		assertEquals(t, fmt.Sprintf(`{"Algorithm":%q}`, info.Name), string(encoded), "MarshalText")
+		var decoded struct{ Algorithm HashAlgorithm }
+		assertEquals(t, nil, json.Unmarshal(encoded, &decoded), "UnmarshalText")
+		assertEquals(t, hashAlgorithm, decoded.Algorithm, "UnmarshalText round trip")
+	}
+	var sha256Info, _ = Sha256.Info()
+	assertEquals(t, "HS256/-16/sha-256/4", fmt.Sprintf("%v/%v/%v/%v", sha256Info.JOSE, sha256Info.COSE,
+		sha256Info.IANA, sha256Info.TLS), "SHA-256 protocol identifiers")
+	sha256Info.Aliases[0] = "changed"
+	var again, _ = Sha256.Info()
+	assertEquals(t, "SHA256", again.Aliases[0], "Info returns a copy")
+	assertEquals(t, "None", None.String(), "None")
+	assertEquals(t, "HashAlgorithm(99)", HashAlgorithm(99).String(), "unregistered")
+	assertEquals(t, None, HashAlgorithmFromCryptoHash(crypto.MD5), "FromCryptoHash MD5")
+	var _, err = ParseHashAlgorithm("MD5")
+	assertEquals(t, true, err != nil, "ParseHashAlgorithm(MD5)")
+	_, err = json.Marshal(HashAlgorithm(99))
+	assertEquals(t, true, err != nil, "MarshalText(99)")
+}
+
+// hardwareSha256 stands in for a third-party (e.g. hardware-backed) implementation registered under a new value
+type hardwareSha256 struct {
+	Hasher
+}
+
+const hardwareSha256Algorithm HashAlgorithm = 1000
+
+func (hasher hardwareSha256) HashAlgorithm() HashAlgorithm {
+	return hardwareSha256Algorithm
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	RegisterAlgorithm(hardwareSha256Algorithm, AlgorithmInfo{Name: "HW-SHA-256", Aliases: []string{"hwsha256"},
+		Size: 32, BlockSize: 64, CollisionStrength: 128, PreimageStrength: 256},
+		func() Hasher { return hardwareSha256{New(Sha256)} })
+	defer UnregisterAlgorithm(hardwareSha256Algorithm)
+
+	var instance = New(hardwareSha256Algorithm)
+	instance.Write([]byte("abc"))
+	assertEquals(t, hardwareSha256Algorithm, instance.HashAlgorithm(), "HashAlgorithm")
+	assertEquals(t, SelfTestVectors[Sha256], fmt.Sprintf("%x", instance.Sum()), "Sum")
+	assertEquals(t, "HW-SHA-256", hardwareSha256Algorithm.String(), "String")
+	var parsed, err = ParseHashAlgorithm("HWSHA256")
+	assertEquals(t, hardwareSha256Algorithm, parsed, fmt.Sprintf("ParseHashAlgorithm: %v", err))
+	var available = Algorithms()
+	assertEquals(t, 12, len(available), "Algorithms")
+	assertEquals(t, Sha224, available[0], "Algorithms ascending")
+	assertEquals(t, hardwareSha256Algorithm, available[len(available)-1], "Algorithms includes registered")
+	assertEquals(t, nil, SecurityPolicy.Check(DigitalSignature, hardwareSha256Algorithm, time.Now()),
+		"policy uses the registered size")
+	assertEquals(t, false, SecurityPolicy.Approved(DigitalSignature, hardwareSha256Algorithm, time.Now()),
+		"registered algorithms are not approved")
+}
+
+func TestRegisterAlgorithm_SharedCryptoHash(t *testing.T) {
+	// Implementations registered as crypto.SHA256 never shadow the built-in, and the lookup is deterministic
+	for _, hashAlgorithm := range []HashAlgorithm{hardwareSha256Algorithm + 1, hardwareSha256Algorithm} {
+		RegisterAlgorithm(hashAlgorithm, AlgorithmInfo{Name: fmt.Sprint("HW-SHA-256-", uint32(hashAlgorithm)),
+			CryptoHash: crypto.SHA256, Size: 32, BlockSize: 64}, func() Hasher { return hardwareSha256{New(Sha256)} })
+		defer UnregisterAlgorithm(hashAlgorithm)
+	}
+	for i := 0; i < 20; i++ { // Map iteration order would vary
+		assertEquals(t, Sha256, HashAlgorithmFromCryptoHash(crypto.SHA256), "built-in first")
+	}
+	for _, hashAlgorithm := range []HashAlgorithm{hardwareSha256Algorithm + 3, hardwareSha256Algorithm + 2} {
+		RegisterAlgorithm(hashAlgorithm, AlgorithmInfo{Name: fmt.Sprint("HW-SHA3-256-", uint32(hashAlgorithm)),
+			CryptoHash: crypto.SHA3_256, Size: 32, BlockSize: 136}, func() Hasher { return hardwareSha256{New(Sha256)} })
+		defer UnregisterAlgorithm(hashAlgorithm)
+	}
+	for i := 0; i < 20; i++ {
+		assertEquals(t, hardwareSha256Algorithm+2, HashAlgorithmFromCryptoHash(crypto.SHA3_256),
+			"lowest registered HashAlgorithm")
+	}
+}
+
+func TestTypedHasher(t *testing.T) {
+	var sums = []interface{}{
+		NewSha224().Write([]byte("abc")).Sum(), NewSha256().Write([]byte("abc")).Sum(),
+		NewSha384().Write([]byte("abc")).Sum(), NewSha512().Write([]byte("abc")).Sum(),
+		NewSha512t224().Write([]byte("abc")).Sum(), NewSha512t256().Write([]byte("abc")).Sum(),
+		NewRipemd160().Write([]byte("abc")).Sum(), NewBlake2b().Write([]byte("abc")).Sum(),
+		NewBlake2s().Write([]byte("abc")).Sum(), NewSha256t192().Write([]byte("abc")).Sum(),
+		NewShake256t192().Write([]byte("abc")).Sum(),
+	}
+	for index, sum := range sums {
+		var hashAlgorithm = Sha224 + HashAlgorithm(index)
+		assertEquals(t, SelfTestVectors[hashAlgorithm], fmt.Sprintf("%x", sum), hashAlgorithm)
+	}
+
+	var typed = NewTyped[[32]byte](Sha512t256).Write(bMsg[:100])
+	var interim [32]byte = typed.InterimSum()
+	var duplicate = typed.Copy().Write(bMsg[100:200])
+	assertEquals(t, interim, typed.Sum(), "InterimSum")
+	assertEquals(t, New(Sha512t256).Write(bMsg[:200]).Sum(), duplicate.Sum(), "Copy")
+	assertEquals(t, Sha512t256, typed.Hasher().HashAlgorithm(), "Hasher")
+	var abc = Sha256Digest(sha256.Sum256([]byte("abc")))
+	assertEquals(t, abc, NewSha256().WriteBits([]byte("abcd"), 24).Sum(), "WriteBits")
+	var reused = NewSha256()
+	reused.Write([]byte("first")).Sum()
+	assertEquals(t, abc, reused.Reset().Write([]byte("abc")).Sum(), "Reset")
+}
+
+func TestSumInto(t *testing.T) {
+	for _, hashAlgorithm := range Algorithms() {
+		var instance = New(hashAlgorithm).Write(bMsg[:333])
+		var expected = fmt.Sprintf("%x", instance.InterimSum())
+		var buffer = make([]byte, 80)
+		var digest = instance.SumInto(buffer)
+		assertEquals(t, expected, fmt.Sprintf("%x", digest), fmt.Sprintf("%v SumInto", hashAlgorithm))
+		assertEquals(t, instance.Size(), len(digest), fmt.Sprintf("%v SumInto length", hashAlgorithm))
+		assertEquals(t, true, isZero(buffer[len(digest):]), fmt.Sprintf("%v SumInto overrun", hashAlgorithm))
+		assertEquals(t, expected, fmt.Sprintf("%x", instance.Sum()), fmt.Sprintf("%v Sum after SumInto",
+			hashAlgorithm))
+	}
+}
+
+func TestAllocations(t *testing.T) {
+	var buffer [64]byte
+	for _, hashAlgorithm := range Algorithms() {
+		var instance = New(hashAlgorithm)
+		var allocations = testing.AllocsPerRun(20, func() {
+			instance.Reset()
+			for _, length := range []int{1, 63, 64, 65, 127, 128, 129, 135, 136, 1000, 7} { // Aligned and not
+				instance.Write(bMsg[:length])
+			}
+			instance.SumInto(buffer[:])
+		})
+		assertEquals(t, 0.0, allocations, fmt.Sprintf("%v Write and SumInto allocations", hashAlgorithm))
+	}
+}
+
+func TestKernels(t *testing.T) {
+	var available256, available512 = Kernels()
+	t.Logf("assembly kernels available: SHA-256 %v, SHA-512 %v", available256, available512)
+	defer UseKernels(-1, -1)() // Restores the kernels selected at init
+	var references = map[HashAlgorithm]func([]byte) []byte{
+		Sha224:     func(message []byte) []byte { sum := sha256.Sum224(message); return sum[:] },
+		Sha256:     func(message []byte) []byte { sum := sha256.Sum256(message); return sum[:] },
+		Sha384:     func(message []byte) []byte { sum := sha512.Sum384(message); return sum[:] },
+		Sha512:     func(message []byte) []byte { sum := sha512.Sum512(message); return sum[:] },
+		Sha512t256: func(message []byte) []byte { sum := sha512.Sum512_256(message); return sum[:] },
+	}
+
+	// The pure-Go path (-1) and then each assembly kernel this CPU supports, against the reference and each other
+	var digests = map[string]string{}
+	for kernel := -1; kernel < max(available256, available512); kernel++ {
+		UseKernels(min(kernel, available256-1), min(kernel, available512-1))
+		for hashAlgorithm, reference := range references {
+			for length := 0; length <= 1100; length += 13 {
+				var message = bMsg[length : 2*length]
+				var split = length / 3
+				var actual = fmt.Sprintf("%x", New(hashAlgorithm).Write(message[:split]).Write(message[split:]).Sum())
+				assertEquals(t, fmt.Sprintf("%x", reference(message)), actual,
+					fmt.Sprintf("%v kernel %v length %v", hashAlgorithm, kernel, length))
+				var key = fmt.Sprint(hashAlgorithm, length)
+				if expected, ok := digests[key]; ok {
+					assertEquals(t, expected, actual, fmt.Sprintf("%v kernels differ at length %v", hashAlgorithm, length))
+				}
+				digests[key] = actual
+			}
+			var long = fmt.Sprintf("%x", New(hashAlgorithm).Write(bMsg).Sum()) // Many blocks in one kernel call
+			assertEquals(t, fmt.Sprintf("%x", reference(bMsg)), long, fmt.Sprintf("%v kernel %v", hashAlgorithm, kernel))
+		}
+	}
+}
+
+func TestHashMany(t *testing.T) {
+	for _, count := range []int{0, 1, 7, 3000} { // 3000 is spread over goroutines
+		var messages = make([][]byte, count)
+		for i := range messages {
+			messages[i] = bMsg[i%100 : i%100+i%300]
+		}
+		for _, hashAlgorithm := range Algorithms() {
+			var digests = HashMany(hashAlgorithm, messages)
+			assertEquals(t, count, len(digests), fmt.Sprintf("%v HashMany count", hashAlgorithm))
+			for i, message := range messages {
+				assertEquals(t, fmt.Sprintf("%x", New(hashAlgorithm).Write(message).Sum()), hex.EncodeToString(digests[i]),
+					fmt.Sprintf("%v HashMany message %v of %v", hashAlgorithm, i, count))
+			}
+		}
+	}
+}
+
+func TestHashMany_Concurrent(t *testing.T) {
+	// The pure-Go kernels (as with -tags purego) on several goroutines; run with -race
+	defer UseKernels(-1, -1)()
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	var messages = make([][]byte, 20000)
+	for i := range messages {
+		messages[i] = bMsg[i%1000 : i%1000+i%700]
+	}
+	for _, hashAlgorithm := range []HashAlgorithm{Sha256, Sha512} {
+		for i, digest := range HashMany(hashAlgorithm, messages) {
+			var expected = map[HashAlgorithm]func([]byte) string{
+				Sha256: func(message []byte) string { sum := sha256.Sum256(message); return hex.EncodeToString(sum[:]) },
+				Sha512: func(message []byte) string { sum := sha512.Sum512(message); return hex.EncodeToString(sum[:]) },
+			}[hashAlgorithm](messages[i])
+			if hex.EncodeToString(digest) != expected {
+				t.Fatalf("%v HashMany message %v: expected %v, got %x", hashAlgorithm, i, expected, digest)
+			}
+		}
+	}
+}
+
+func TestHashFiles(t *testing.T) {
+	var directory = t.TempDir()
+	var paths []string
+	for i := 0; i < 40; i++ {
+		var path = filepath.Join(directory, fmt.Sprintf("file%02d", i))
+		if err := os.WriteFile(path, bMsg[:i*97], 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	paths = append(paths, filepath.Join(directory, "missing"), directory) // Per-file errors
+	var options = FileOptions{Algorithms: []HashAlgorithm{Sha256, Sha512}, Workers: 3}
+	var index = 0
+	for result := range HashFiles(context.Background(), paths, options) {
+		assertEquals(t, paths[index], result.Path, "HashFiles order")
+		if index < 40 {
+			assertEquals(t, nil, result.Err, result.Path)
+			assertEquals(t, int64(index*97), result.Size, result.Path)
+			for _, hashAlgorithm := range options.Algorithms {
+				assertEquals(t, fmt.Sprintf("%x", New(hashAlgorithm).Write(bMsg[:index*97]).Sum()),
+					hex.EncodeToString(result.Digests[hashAlgorithm]), result.Path)
+			}
+		} else {
+			assertEquals(t, true, result.Err != nil, fmt.Sprintf("%v error", result.Path))
+		}
+		index++
+	}
+	assertEquals(t, len(paths), index, "HashFiles results")
+
+	// Cancelling stops the stream early
+	var ctx, cancel = context.WithCancel(context.Background())
+	var results = HashFiles(ctx, paths, options)
+	<-results
+	cancel()
+	for range results {
+	}
+}
+
+func TestHashFiles_Concurrent(t *testing.T) {
+	// Workers on the pure-Go kernels (as with -tags purego), both through the mapping and SumInto; run with -race
+	defer UseKernels(-1, -1)()
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	var directory = t.TempDir()
+	var paths []string
+	for i := 0; i < 64; i++ {
+		var path = filepath.Join(directory, fmt.Sprintf("file%02d", i))
+		if err := os.WriteFile(path, bMsg[i:i*100+i], 0o600); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	var check = func(result FileDigest, content []byte) {
+		var sum256, sum512 = sha256.Sum256(content), sha512.Sum512(content)
+		assertEquals(t, hex.EncodeToString(sum256[:]), hex.EncodeToString(result.Digests[Sha256]), result.Path)
+		assertEquals(t, hex.EncodeToString(sum512[:]), hex.EncodeToString(result.Digests[Sha512]), result.Path)
+	}
+	var options = FileOptions{Algorithms: []HashAlgorithm{Sha256, Sha512}, Workers: 8}
+	var index = 0
+	for result := range HashFiles(context.Background(), paths, options) {
+		check(result, bMsg[index:index*100+index])
+		index++
+	}
+	index = 0
+	for result := range HashTree(context.Background(), directory, options) {
+		check(result, bMsg[index:index*100+index]) // Lexical order matches the file numbering
+		index++
+	}
+	assertEquals(t, 64, index, "HashTree results")
+}
+
+func TestHashTree(t *testing.T) {
+	var root = t.TempDir()
+	var expected []string
+	for _, name := range []string{"b/2", "a/1", "a/c/3", "d", "a/0"} {
+		var path = filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(name), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{"a/0", "a/1", "a/c/3", "b/2", "d"} { // Lexical walk order
+		expected = append(expected, filepath.Join(root, filepath.FromSlash(name)))
+	}
+	var actual []string
+	for result := range HashTree(context.Background(), root, FileOptions{}) {
+		assertEquals(t, nil, result.Err, result.Path)
+		var name = filepath.ToSlash(strings.TrimPrefix(result.Path, root+string(filepath.Separator)))
+		assertEquals(t, fmt.Sprintf("%x", New(Sha256).Write([]byte(name)).Sum()),
+			hex.EncodeToString(result.Digests[Sha256]), result.Path)
+		actual = append(actual, result.Path)
+	}
+	assertEquals(t, fmt.Sprint(expected), fmt.Sprint(actual), "HashTree paths")
+
+	var missing = 0
+	for result := range HashTree(context.Background(), filepath.Join(root, "missing"), FileOptions{}) {
+		assertEquals(t, true, result.Err != nil, "HashTree of a missing root")
+		missing++
+	}
+	assertEquals(t, 1, missing, "HashTree of a missing root results")
+}
+
+// cancellingReader returns the message a few bytes at a time and cancels its context after cancelAfter reads
+type cancellingReader struct {
+	message     []byte
+	reads       int
+	cancelAfter int
+	cancel      context.CancelFunc
+}
+
+func (reader *cancellingReader) Read(buffer []byte) (int, error) {
+	if reader.reads++; reader.reads == reader.cancelAfter {
+		reader.cancel()
+	}
+	if len(reader.message) == 0 {
+		return 0, io.EOF
+	}
+	var length = copy(buffer[:min(len(buffer), 7)], reader.message)
+	reader.message = reader.message[length:]
+	return length, nil
+}
+
+func TestHashReader(t *testing.T) {
+	var expected = fmt.Sprintf("%x", New(Sha256).Write(bMsg).Sum())
+
+	// Slow (one byte and half buffer) readers with progress callbacks
+	for _, reader := range []io.Reader{iotest.OneByteReader(bytes.NewReader(bMsg)), iotest.HalfReader(bytes.NewReader(bMsg)),
+		iotest.DataErrReader(bytes.NewReader(bMsg))} {
+		var progress []uint64
+		digest, count, err := HashReader(context.Background(), Sha256, reader, ReaderOptions{BufferSize: 1000,
+			ProgressInterval: 3000, Progress: func(processed uint64) { progress = append(progress, processed) }})
+		assertEquals(t, nil, err, "HashReader error")
+		assertEquals(t, expected, hex.EncodeToString(digest), "HashReader digest")
+		assertEquals(t, uint64(len(bMsg)), count, "HashReader count")
+		assertEquals(t, 3, len(progress), fmt.Sprintf("HashReader progress %v", progress))
+		for i, processed := range progress[:2] { // At the first read reaching each interval
+			assertEquals(t, true, processed >= uint64(3000*(i+1)) && processed < uint64(3000*(i+1)+1000),
+				fmt.Sprintf("HashReader progress %v", progress))
+		}
+		assertEquals(t, uint64(8192), progress[len(progress)-1], "HashReader final progress")
+	}
+
+	// Limits
+	digest, count, err := HashReader(context.Background(), Sha256, bytes.NewReader(bMsg), ReaderOptions{Limit: 8192})
+	assertEquals(t, expected, hex.EncodeToString(digest), fmt.Sprintf("HashReader at the limit: %v %v", count, err))
+	_, count, err = HashReader(context.Background(), Sha256, bytes.NewReader(bMsg), ReaderOptions{Limit: 8191})
+	assertEquals(t, ErrLimitExceeded, err, fmt.Sprintf("HashReader beyond the limit: %v", count))
+
+	// Cancellation, and read errors
+	var ctx, cancel = context.WithCancel(context.Background())
+	digest, count, err = HashReader(ctx, Sha256, &cancellingReader{message: bMsg, cancelAfter: 10, cancel: cancel},
+		ReaderOptions{})
+	assertEquals(t, context.Canceled, err, "HashReader cancelled")
+	assertEquals(t, uint64(70), count, "HashReader count when cancelled")
+	assertEquals(t, 0, len(digest), "HashReader digest when cancelled")
+	_, _, err = HashReader(context.Background(), Sha256, iotest.ErrReader(iotest.ErrTimeout), ReaderOptions{})
+	assertEquals(t, iotest.ErrTimeout, err, "HashReader read error")
+}
+
+func TestHashFile(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "large")
+	var large = make([]byte, 3<<20+77)
+	rand.Read(large)
+	if err := os.WriteFile(path, large, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, hashAlgorithm := range []HashAlgorithm{Sha256, Sha512, Blake2b} {
+		digest, count, err := HashFile(hashAlgorithm, path)
+		assertEquals(t, nil, err, "HashFile error")
+		assertEquals(t, uint64(len(large)), count, "HashFile count")
+		assertEquals(t, fmt.Sprintf("%x", New(hashAlgorithm).Write(large).Sum()), hex.EncodeToString(digest),
+			fmt.Sprintf("%v HashFile", hashAlgorithm))
+	}
+
+	// Special files and pipes are read through a buffer
+	digest, count, err := HashFile(Sha256, os.DevNull)
+	assertEquals(t, fmt.Sprintf("%x", New(Sha256).Sum()), hex.EncodeToString(digest), fmt.Sprintf("%v %v", count, err))
+	if runtime.GOOS == "linux" {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() { writer.Write(bMsg); writer.Close() }()
+		digest, _, err = HashFile(Sha256, fmt.Sprintf("/proc/self/fd/%d", reader.Fd()))
+		reader.Close()
+		assertEquals(t, fmt.Sprintf("%x", New(Sha256).Write(bMsg).Sum()), hex.EncodeToString(digest),
+			fmt.Sprintf("HashFile of a pipe: %v", err))
+
+		// procfs and sysfs report sizes (0 or a page) unrelated to their content, which is hashed to EOF
+		for _, special := range []string{"/proc/self/cmdline", "/sys/kernel/mm/transparent_hugepage/enabled"} {
+			content, err := os.ReadFile(special)
+			if err != nil {
+				continue
+			}
+			digest, count, err = HashFile(Sha256, special)
+			assertEquals(t, nil, err, fmt.Sprintf("HashFile of %v", special))
+			assertEquals(t, uint64(len(content)), count, fmt.Sprintf("HashFile count of %v", special))
+			assertEquals(t, fmt.Sprintf("%x", sha256.Sum256(content)), hex.EncodeToString(digest), special)
+		}
+	}
+	_, _, err = HashFile(Sha256, filepath.Join(t.TempDir(), "missing"))
+	assertEquals(t, true, os.IsNotExist(err), "HashFile of a missing file")
+
+	// Files that shrink (a fault on the mapping where files are mapped) or grow while being hashed, through a
+	// mapping and through buffered reads
+	defer AfterStart(nil)
+	for _, mapping := range []bool{true, false} {
+		var restore = UseMapping(mapping)
+		os.WriteFile(path, large, 0o600)
+		AfterStart(func(file *os.File) { os.Truncate(path, 1000) })
+		_, _, err = HashFile(Sha256, path)
+		assertEquals(t, ErrFileChanged, err, fmt.Sprintf("HashFile of a shrinking file (mapping %v)", mapping))
+		os.WriteFile(path, large, 0o600)
+		AfterStart(func(file *os.File) {
+			appender, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+			appender.Write([]byte("more"))
+			appender.Close()
+		})
+		_, _, err = HashFile(Sha256, path)
+		assertEquals(t, ErrFileChanged, err, fmt.Sprintf("HashFile of a growing file (mapping %v)", mapping))
+		AfterStart(nil)
+		_, _, err = HashFile(Sha256, path)
+		assertEquals(t, nil, err, fmt.Sprintf("HashFile of an unchanged file (mapping %v)", mapping))
+		restore()
+	}
+}
+
+func TestMultiHasher(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		var multi = NewMulti(Sha256, Sha384, Sha512, Blake2s).Parallel(parallel)
+		multi.Write(bMsg[:100])
+		var duplicate = multi.Copy()
+		var interim = multi.InterimSum()
+		var large = make([]byte, 200000) // Long enough to fan out
+		multi.Write(large).WriteBits([]byte{0x80}, 8)
+		duplicate.Write(large).WriteBits([]byte{0x80}, 8)
+		assertEquals(t, uint64(100+len(large)+1), multi.Len(), "MultiHasher Len")
+		var sums, duplicateSums = multi.Sum(), duplicate.Sum()
+		assertEquals(t, fmt.Sprint([]HashAlgorithm{Sha256, Sha384, Sha512, Blake2s}), fmt.Sprint(multi.Algorithms()),
+			"MultiHasher Algorithms")
+		for _, hashAlgorithm := range multi.Algorithms() {
+			var expected = New(hashAlgorithm).Write(bMsg[:100]).Write(large).Write([]byte{0x80})
+			assertEquals(t, fmt.Sprintf("%x", expected.Sum()), hex.EncodeToString(sums[hashAlgorithm]),
+				fmt.Sprintf("%v parallel %v Sum", hashAlgorithm, parallel))
+			assertEquals(t, hex.EncodeToString(sums[hashAlgorithm]), hex.EncodeToString(duplicateSums[hashAlgorithm]),
+				fmt.Sprintf("%v parallel %v Copy", hashAlgorithm, parallel))
+			assertEquals(t, fmt.Sprintf("%x", New(hashAlgorithm).Write(bMsg[:100]).Sum()),
+				hex.EncodeToString(interim[hashAlgorithm]), fmt.Sprintf("%v InterimSum", hashAlgorithm))
+			assertEquals(t, hashAlgorithm, multi.Hasher(hashAlgorithm).HashAlgorithm(), "MultiHasher Hasher")
+		}
+		assertEquals(t, fmt.Sprintf("%x", New(Sha256).Sum()), hex.EncodeToString(multi.Reset().Sum()[Sha256]),
+			"MultiHasher Reset")
+	}
+}
+
+func TestMultiHasher_Parallel(t *testing.T) {
+	// The release manifest case on the pure-Go kernels (as with -tags purego or off amd64); run with -race
+	defer UseKernels(-1, -1)()
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(8))
+	var data = make([]byte, 1<<20)
+	rand.Read(data)
+	var sums = NewMulti(Sha256, Sha384, Sha512).Parallel(true).Write(data).Write(data[:1000]).Sum()
+	for _, hashAlgorithm := range []HashAlgorithm{Sha256, Sha384, Sha512} {
+		assertEquals(t, fmt.Sprintf("%x", New(hashAlgorithm).Write(data).Write(data[:1000]).Sum()),
+			hex.EncodeToString(sums[hashAlgorithm]), fmt.Sprintf("%v parallel MultiHasher", hashAlgorithm))
+	}
+}
+
+func TestHashingReaderWriter(t *testing.T) {
+	var reader = NewHashingReader(iotest.HalfReader(bytes.NewReader(bMsg)), New(Sha256))
+	var partial = make([]byte, 1000)
+	io.ReadFull(reader, partial)
+	assertEquals(t, fmt.Sprintf("%x", New(Sha256).Write(bMsg[:1000]).Sum()), fmt.Sprintf("%x", reader.InterimSum()),
+		"HashingReader InterimSum")
+	var rest, _ = io.ReadAll(reader)
+	assertEquals(t, len(bMsg)-1000, len(rest), "HashingReader passes the content through")
+	assertEquals(t, fmt.Sprintf("%x", New(Sha256).Write(bMsg).Sum()), fmt.Sprintf("%x", reader.Hasher().Sum()),
+		"HashingReader Sum")
+
+	var copied bytes.Buffer
+	var writer = NewHashingWriter(&copied, New(Sha512))
+	writer.Write(bMsg[:100])
+	assertEquals(t, fmt.Sprintf("%x", New(Sha512).Write(bMsg[:100]).Sum()), fmt.Sprintf("%x", writer.InterimSum()),
+		"HashingWriter InterimSum")
+	io.Copy(writer, bytes.NewReader(bMsg[100:]))
+	assertEquals(t, true, bytes.Equal(bMsg, copied.Bytes()), "HashingWriter passes the content through")
+	assertEquals(t, fmt.Sprintf("%x", New(Sha512).Write(bMsg).Sum()), fmt.Sprintf("%x", writer.Hasher().Sum()),
+		"HashingWriter Sum")
+	assertEquals(t, fmt.Sprintf("%x", New(Sha512).Write(bMsg).Sum()),
+		fmt.Sprintf("%x", NewHashingWriter(nil, New(Sha512)).Hasher().Write(bMsg).Sum()), "HashingWriter without a writer")
+}
+
+func TestVerifyingReader(t *testing.T) {
+	var content = make([]byte, 100000) // Several withheld chunks
+	rand.Read(content)
+	var digest = New(Sha256).Write(content).Sum().([32]byte)
+	var tampered = append([]byte(nil), content...)
+	tampered[len(tampered)-1] ^= 1
+
+	for _, withhold := range []bool{false, true} {
+		for _, reader := range []func([]byte) io.Reader{
+			func(b []byte) io.Reader { return bytes.NewReader(b) },
+			func(b []byte) io.Reader { return iotest.OneByteReader(bytes.NewReader(b)) },
+			func(b []byte) io.Reader { return iotest.DataErrReader(bytes.NewReader(b)) },
+		} {
+			var verified, err = io.ReadAll(NewVerifyingReader(reader(content), New(Sha256), digest[:], withhold))
+			assertEquals(t, nil, err, fmt.Sprintf("VerifyingReader withhold %v", withhold))
+			assertEquals(t, true, bytes.Equal(content, verified), fmt.Sprintf("VerifyingReader withhold %v content", withhold))
+
+			var released []byte
+			released, err = io.ReadAll(NewVerifyingReader(reader(tampered), New(Sha256), digest[:], withhold))
+			assertEquals(t, true, errors.Is(err, ErrIntegrity), fmt.Sprintf("VerifyingReader withhold %v: %v", withhold, err))
+			var integrityError *IntegrityError
+			assertEquals(t, true, errors.As(err, &integrityError) && integrityError.HashAlgorithm == Sha256,
+				"VerifyingReader IntegrityError")
+			if withhold {
+				assertEquals(t, true, len(released) < len(tampered) && bytes.Equal(tampered[:len(released)], released),
+					fmt.Sprintf("VerifyingReader withheld the last chunk: %v of %v", len(released), len(tampered)))
+			} else {
+				assertEquals(t, len(tampered), len(released), "VerifyingReader without withholding")
+			}
+		}
+	}
+
+	// Empty content
+	var empty = New(Sha256).Sum().([32]byte)
+	var verified, err = io.ReadAll(NewVerifyingReader(bytes.NewReader(nil), New(Sha256), empty[:], true))
+	assertEquals(t, 0, len(verified), fmt.Sprintf("VerifyingReader of empty content: %v", err))
+}
+
+func TestMidstate(t *testing.T) {
+	// BIP-340 tagged hash: SHA-256(SHA-256(tag) || SHA-256(tag) || message), the prefix being one whole block
+	var tag = New(Sha256).Write([]byte("BIP0340/challenge")).Sum().([32]byte)
+	var prefix = append(tag[:], tag[:]...)
+	var midstate = ExportMidstate(New(Sha256).Write(prefix))
+	assertEquals(t, uint64(64), midstate.Length, "Midstate length")
+	encoded, _ := json.Marshal(midstate)
+	var decoded Midstate
+	json.Unmarshal(encoded, &decoded)
+	for _, length := range []int{0, 1, 32, 64, 200} {
+		var tagged = NewFromMidstate(decoded).Write(bMsg[:length])
+		assertEquals(t, false, tagged.Approved(), "Imported midstate is not approved")
+		assertEquals(t, fmt.Sprintf("%x", sha256.Sum256(append(append([]byte(nil), prefix...), bMsg[:length]...))),
+			fmt.Sprintf("%x", tagged.Sum()), fmt.Sprintf("Tagged hash of %v bytes", length))
+	}
+
+	// The SHA-512 family, after several blocks
+	for _, hashAlgorithm := range []HashAlgorithm{Sha224, Sha384, Sha512, Sha512t224, Sha512t256, Sha256t192} {
+		var blocks = 3 * New(hashAlgorithm).BlockSize()
+		var resumed = NewFromMidstate(ExportMidstate(New(hashAlgorithm).Write(bMsg[:blocks]))).Write(bMsg[blocks:])
+		assertEquals(t, fmt.Sprintf("%x", New(hashAlgorithm).Write(bMsg).Sum()), fmt.Sprintf("%x", resumed.Sum()),
+			fmt.Sprintf("%v resumed from a midstate", hashAlgorithm))
+		assertEquals(t, false, resumed.Approved(), fmt.Sprintf("%v resumed is not approved", hashAlgorithm))
+		assertEquals(t, false, resumed.Copy().Approved(), fmt.Sprintf("%v resumed copy is not approved", hashAlgorithm))
+		assertEquals(t, New(hashAlgorithm).Approved(), resumed.Reset().Approved(), fmt.Sprintf("%v after Reset",
+			hashAlgorithm))
+	}
+
+	// Custom IVs: the SHA-512/256 IV in SHA-512 gives SHA-512/256 untruncated, and is not approved
+	var iv = ExportMidstate(New(Sha512t256))
+	iv.HashAlgorithm = Sha512
+	var custom = NewFromMidstate(iv).Write(bMsg)
+	assertEquals(t, false, custom.Approved(), "Custom IV is not approved")
+	assertEquals(t, fmt.Sprintf("%x", New(Sha512t256).Write(bMsg).Sum()), fmt.Sprintf("%x", custom.Sum())[:64],
+		"SHA-512 with the SHA-512/256 IV")
+	assertEquals(t, false, NewFromMidstate(ExportMidstate(New(Sha256))).Approved(), "Imported standard IV")
+}
+
+func TestLengthExtension(t *testing.T) {
+	var secret = []byte("an unknown secret")
+	var extension = []byte(";admin=true")
+	for _, hashAlgorithm := range []HashAlgorithm{Sha256, Sha512} {
+		for _, length := range []int{0, 1, 38, 39, 46, 47, 55, 56, 63, 64, 94, 95, 111, 112, 200} {
+			var message = append(append([]byte(nil), secret...), bMsg[:length]...)
+			var mac = New(hashAlgorithm).Write(message).SumInto(make([]byte, 64))
+
+			// The attacker knows only the MAC, the total length, and the extension
+			var forged = NewLengthExtension(hashAlgorithm, mac, uint64(len(message))).Write(extension).Sum()
+			var glue = GluePadding(hashAlgorithm, uint64(len(message)))
+			assertEquals(t, 0, (len(message)+len(glue))%New(hashAlgorithm).BlockSize(), "GluePadding ends a block")
+			var direct = New(hashAlgorithm).Write(message).Write(glue).Write(extension).Sum()
+			assertEquals(t, fmt.Sprintf("%x", direct), fmt.Sprintf("%x", forged),
+				fmt.Sprintf("%v length extension of %v bytes", hashAlgorithm, len(message)))
+			assertEquals(t, false, NewLengthExtension(hashAlgorithm, mac, uint64(len(message))).Approved(),
+				fmt.Sprintf("%v length extension is not approved", hashAlgorithm))
+		}
+	}
+	assertEquals(t, "80"+strings.Repeat("00", 52)+"0000000000000018", hex.EncodeToString(GluePadding(Sha256, 3)),
+		"SHA-256 GluePadding of \"abc\"")
+	assertEquals(t, "80"+strings.Repeat("00", 111)+"00000000000000000000000000000400",
+		hex.EncodeToString(GluePadding(Sha384, 128)), "SHA-384 GluePadding of a whole block")
+}
+
 var hitThis bool
 
 func hitIt(_ ...interface{}) { hitThis = true }
@@ -618,6 +1931,27 @@ func TestBadAlgorithm99(t *testing.T) {
 	assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", instance))
 }
 
+func TestBadBlake2Parameters(t *testing.T) {
+	var testCases = []struct {
+		hashAlgorithm HashAlgorithm
+		parameters    Blake2Parameters
+	}{
+		{Blake2b, Blake2Parameters{Size: 65}},
+		{Blake2s, Blake2Parameters{Size: 33}},
+		{Blake2b, Blake2Parameters{Size: -1}},
+		{Blake2b, Blake2Parameters{Key: make([]byte, 65)}},
+		{Blake2s, Blake2Parameters{Salt: make([]byte, 9)}},
+		{Blake2s, Blake2Parameters{Personalization: make([]byte, 9)}},
+		{Sha256, Blake2Parameters{}},
+	}
+	for _, tt := range testCases {
+		LogFatal = hitIt
+		hitThis = false
+		NewBlake2(tt.hashAlgorithm, tt.parameters)
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", tt))
+	}
+}
+
 func TestBadWriteAfterSum256(t *testing.T) {
 	LogFatal = hitIt
 	hitThis = false
@@ -636,6 +1970,240 @@ func TestBadWriteAfterSum512(t *testing.T) {
 	assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", sum))
 }
 
+func TestBadWriteAfterPartialByte(t *testing.T) {
+	for _, hashAlgorithm := range []HashAlgorithm{Sha256, Sha512} {
+		LogFatal = hitIt
+		hitThis = false
+		var instance = New(hashAlgorithm).WriteBits([]byte{0xff}, 3)
+		instance.Write([]byte("this cannot be good"))
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", hashAlgorithm))
+	}
+}
+
+func TestBadWriteBits(t *testing.T) {
+	var testCases = []struct {
+		hashAlgorithm HashAlgorithm
+		bitLength     uint64
+	}{
+		{Sha256, 17}, {Sha384, 17}, {Ripemd160, 3}, {Blake2b, 7}, {Blake2s, 9}, {Shake256t192, 1},
+	}
+	for _, tt := range testCases {
+		LogFatal = hitIt
+		hitThis = false
+		New(tt.hashAlgorithm).WriteBits([]byte{0xff, 0xff}, tt.bitLength)
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", tt))
+	}
+}
+
+func TestBadSelfTest(t *testing.T) {
+	var saved = SelfTestVectors[Sha512]
+	defer func() { SelfTestVectors[Sha512] = saved; ResetSelfTest() }()
+	SelfTestVectors[Sha512] = strings.Repeat("00", 64) // Simulated corruption
+	ResetSelfTest()
+	for _, result := range SelfTest() {
+		assertEquals(t, result.HashAlgorithm != Sha512, result.Passed, fmt.Sprintf("%v %v", result.Test,
+			result.HashAlgorithm))
+	}
+	assertEquals(t, true, SelfTestErrorState(), "error state after a failed self-test")
+	for _, construct := range []func() Hasher{
+		func() Hasher { return New(Sha256) },
+		func() Hasher { return NewBlake2(Blake2b, Blake2Parameters{}) },
+	} {
+		LogFatal = hitIt
+		hitThis = false
+		var instance = construct()
+		assertEquals(t, true, hitThis, "LogFatal did not hitIt in the error state")
+		assertEquals(t, true, instance == nil, "hasher constructed in the error state")
+	}
+}
+
+func TestBadPolicy(t *testing.T) {
+	var saved = SecurityPolicy
+	defer func() { SecurityPolicy = saved }()
+	SecurityPolicy = Policy{ApprovedOnly: true, Rules: []PolicyRule{
+		{Purpose: DigitalSignature, MinimumStrength: 128, From: time.Now().Add(-time.Hour)},
+		{Purpose: General, MinimumStrength: 64},
+	}}
+	var testCases = []func() Hasher{
+		func() Hasher { return NewFor(DigitalSignature, Sha224) },
+		func() Hasher { return NewFor(HMAC, Ripemd160) },
+		func() Hasher { return New(Blake2b) },
+		func() Hasher { return NewBlake2(Blake2s, Blake2Parameters{Size: 12}) },
+	}
+	for index, construct := range testCases {
+		LogFatal = hitIt
+		hitThis = false
+		var instance = construct()
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", index))
+		assertEquals(t, true, instance == nil, fmt.Sprintf("hasher constructed against policy: %v", index))
+	}
+}
+
+func TestBadUseAfterDestroy(t *testing.T) {
+	for _, use := range []func(Hasher){
+		func(instance Hasher) { instance.Sum() },
+		func(instance Hasher) { instance.Write([]byte("this cannot be good")) },
+	} {
+		for _, hashAlgorithm := range []HashAlgorithm{Sha256, Sha512, Ripemd160, Blake2b, Blake2s, Shake256t192} {
+			LogFatal = hitIt
+			hitThis = false
+			var instance = New(hashAlgorithm).Write([]byte("message"))
+			instance.Destroy()
+			use(instance)
+			assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", hashAlgorithm))
+		}
+	}
+}
+
+func TestBadZeroizeOnSum(t *testing.T) {
+	LogFatal = hitIt
+	hitThis = false
+	var instance = ZeroizeOnSum(New(Sha256).Write([]byte("message")))
+	instance.Sum()
+	instance.Sum()
+	assertEquals(t, true, hitThis, "LogFatal did not hitIt: Sum after zeroizing Sum")
+	hitThis = false
+	ZeroizeOnSum(hardwareSha256{New(Sha256)})
+	assertEquals(t, true, hitThis, "LogFatal did not hitIt: ZeroizeOnSum unsupported")
+}
+
+func TestBadVerify(t *testing.T) {
+	// Expected digests are often untrusted input: a bad one is a mismatch, never a fatal usage error
+	var sha512t256Sum = New(Sha512t256).Write([]byte("abc")).Sum().([32]byte)
+	var testCases = []func() bool{
+		func() bool { return New(Sha256).Write([]byte("abc")).Verify(make([]byte, 20)) },
+		func() bool { return New(Sha384).Write([]byte("abc")).Verify(sha512t256Sum[:]) },
+		func() bool { return New(Ripemd160).Write([]byte("abc")).Verify(nil) },
+		func() bool { return New(Sha256).Write([]byte("abc")).VerifyHex("not hex") },
+		func() bool { return New(Sha256).Write([]byte("abc")).VerifyBase64("not base64!") },
+	}
+	for index, tt := range testCases {
+		LogFatal = hitIt
+		hitThis = false
+		var verified = tt()
+		assertEquals(t, false, hitThis, fmt.Sprintf("LogFatal hitIt: %v", index))
+		assertEquals(t, false, verified, fmt.Sprintf("verified: %v", index))
+	}
+}
+
+func TestBadResetAfterKeyDestroyed(t *testing.T) {
+	for _, hashAlgorithm := range []HashAlgorithm{Blake2b, Blake2s} {
+		LogFatal = hitIt
+		hitThis = false
+		var instance = NewBlake2(hashAlgorithm, Blake2Parameters{Key: []byte("key")})
+		instance.Destroy()
+		instance.Reset()
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", hashAlgorithm))
+	}
+}
+
+func TestBadRegisterAlgorithm(t *testing.T) {
+	var constructor = func() Hasher { return hardwareSha256{New(Sha256)} }
+	var sha256Info, _ = Sha256.Info()
+	var testCases = []struct {
+		hashAlgorithm HashAlgorithm
+		info          AlgorithmInfo
+		constructor   func() Hasher
+	}{
+		{Sha256, AlgorithmInfo{Name: "Duplicate value", Size: 32}, constructor},
+		{None, AlgorithmInfo{Name: "None", Size: 32}, constructor},
+		{hardwareSha256Algorithm, AlgorithmInfo{Name: "sha-256", Size: 32}, constructor},
+		{hardwareSha256Algorithm, AlgorithmInfo{Name: "New name", Aliases: []string{"SHA2-256"}, Size: 32}, constructor},
+		{hardwareSha256Algorithm, AlgorithmInfo{Name: "New name", OID: sha256Info.OID, Size: 32}, constructor},
+		{hardwareSha256Algorithm, AlgorithmInfo{Name: "New name", Size: 32}, nil},
+		{hardwareSha256Algorithm, AlgorithmInfo{Size: 32}, constructor},
+		{hardwareSha256Algorithm, AlgorithmInfo{Name: "New name"}, constructor},
+	}
+	for index, tt := range testCases {
+		LogFatal = hitIt
+		hitThis = false
+		RegisterAlgorithm(tt.hashAlgorithm, tt.info, tt.constructor)
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", index))
+		UnregisterAlgorithm(hardwareSha256Algorithm)
+	}
+	assertEquals(t, "SHA-256", Sha256.String(), "built-in entry untouched")
+}
+
+func TestBadNewTyped(t *testing.T) {
+	LogFatal = hitIt
+	hitThis = false
+	var typed = NewTyped[Sha256Digest](Sha384)
+	assertEquals(t, true, hitThis, "LogFatal did not hitIt")
+	assertEquals(t, true, typed == nil, "TypedHasher with mismatched digest size")
+}
+
+func TestBadSumInto(t *testing.T) {
+	LogFatal = hitIt
+	hitThis = false
+	var digest = New(Sha384).Write([]byte("abc")).SumInto(make([]byte, 32))
+	assertEquals(t, true, hitThis, "LogFatal did not hitIt")
+	assertEquals(t, 0, len(digest), "SumInto into a short destination")
+}
+
+func TestBadHashMany(t *testing.T) {
+	LogFatal = hitIt
+	hitThis = false
+	var digests = HashMany(None, [][]byte{{1}}) // Bad hash algorithm
+	assertEquals(t, true, hitThis, "LogFatal did not hitIt")
+	assertEquals(t, 0, len(digests), "HashMany of a bad hash algorithm returned digests")
+}
+
+func TestBadNewMulti(t *testing.T) {
+	LogFatal = hitIt
+	for _, hashAlgorithms := range [][]HashAlgorithm{{}, {Sha256, Sha512, Sha256}, {Sha256, None}} {
+		hitThis = false
+		var multi = NewMulti(hashAlgorithms...)
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: %v", hashAlgorithms))
+		assertEquals(t, true, multi == nil, fmt.Sprintf("NewMulti(%v)", hashAlgorithms))
+	}
+}
+
+func TestBadVerifyingReader(t *testing.T) {
+	LogFatal = hitIt
+	for _, withhold := range []bool{false, true} {
+		hitThis = false
+		var verifier = NewVerifyingReader(bytes.NewReader(bMsg), New(Sha256), make([]byte, 48), withhold) // SHA-384 size
+		var length, err = verifier.Read(make([]byte, 100))
+		assertEquals(t, false, hitThis, "LogFatal hitIt")
+		assertEquals(t, 0, length, fmt.Sprintf("withhold %v length", withhold))
+		assertEquals(t, true, errors.Is(err, ErrDigestLength), fmt.Sprintf("withhold %v: %v", withhold, err))
+	}
+}
+
+func TestBadMidstate(t *testing.T) {
+	LogFatal = hitIt
+	for name, export := range map[string]func() Midstate{
+		"unaligned":   func() Midstate { return ExportMidstate(New(Sha256).Write(bMsg[:65])) },
+		"summed":      func() Midstate { var h = New(Sha512).Write(bMsg[:128]); h.Sum(); return ExportMidstate(h) },
+		"unsupported": func() Midstate { return ExportMidstate(New(Blake2b).Write(bMsg[:128])) },
+	} {
+		hitThis = false
+		export()
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: ExportMidstate %v", name))
+	}
+	var valid = ExportMidstate(New(Sha256).Write(bMsg[:64]))
+	for name, midstate := range map[string]Midstate{
+		"unaligned length":     {HashAlgorithm: Sha256, ChainingValue: valid.ChainingValue, Length: 65},
+		"short chaining value": {HashAlgorithm: Sha512, ChainingValue: valid.ChainingValue, Length: 128},
+		"unsupported":          {HashAlgorithm: Ripemd160, ChainingValue: make([]byte, 20)},
+	} {
+		hitThis = false
+		var hasher = NewFromMidstate(midstate)
+		assertEquals(t, true, hitThis, fmt.Sprintf("LogFatal did not hitIt: NewFromMidstate %v", name))
+		assertEquals(t, true, hasher == nil, fmt.Sprintf("NewFromMidstate %v", name))
+	}
+}
+
+func TestBadLengthExtension(t *testing.T) {
+	LogFatal = hitIt
+	hitThis = false
+	var hasher = NewLengthExtension(Sha384, make([]byte, 48), 10) // Truncated: the chaining value is incomplete
+	assertEquals(t, true, hitThis && hasher == nil, "LogFatal did not hitIt: NewLengthExtension of SHA-384")
+	hitThis = false
+	GluePadding(Blake2s, 10)
+	assertEquals(t, true, hitThis, "LogFatal did not hitIt: GluePadding of BLAKE2s")
+}
+
 var bMsg = []byte{0}
 
 func init() {
@@ -668,3 +2236,40 @@ func BenchmarkGolangSha512(b *testing.B) {
 		sha512.Sum512(bMsg)
 	}
 }
+
+// Many short records: HashMany against New/Write/Sum in a loop
+var shortMessages = func() [][]byte {
+	var messages = make([][]byte, 10000)
+	for i := range messages {
+		messages[i] = []byte(fmt.Sprintf("record-%08d", i))
+	}
+	return messages
+}()
+
+func BenchmarkHashManySha256(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		HashMany(Sha256, shortMessages)
+	}
+}
+
+func BenchmarkLoopSha256(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		for _, message := range shortMessages {
+			New(Sha256).Write(message).Sum()
+		}
+	}
+}
+
+func BenchmarkHashManySha512(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		HashMany(Sha512, shortMessages)
+	}
+}
+
+func BenchmarkLoopSha512(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		for _, message := range shortMessages {
+			New(Sha512).Write(message).Sum()
+		}
+	}
+}